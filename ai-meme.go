@@ -0,0 +1,108 @@
+package imgflipgo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+const AIMemeEndpoint = DefaultBaseURL + "/" + aiMemePath
+
+// AIModel selects the text generation model used by the ai_meme endpoint.
+type AIModel string
+
+const (
+	AIModelOpenAI  AIModel = "openai"
+	AIModelClassic AIModel = "classic"
+)
+
+// MaxAIMemePrefixLen is the maximum length of AIMemeRequest.PrefixText.
+const MaxAIMemePrefixLen = 64
+
+type AIMemeRequest struct {
+	// Username of a valid imgflip account. The ai_meme endpoint requires
+	// authentication.
+	Username string `json:"username,omitempty"`
+
+	// Password for the imgflip account.
+	Password string `json:"password,omitempty"`
+
+	// [optional] The model used to generate text. Defaults to AIModelOpenAI.
+	Model AIModel `json:"model,omitempty"`
+
+	// [optional] The template to caption. If not specified, a random template
+	// is chosen.
+	TemplateID string `json:"template_id,omitempty"`
+
+	// [optional] Text that the generated meme must start with. Limited to
+	// MaxAIMemePrefixLen characters, and only used by AIModelOpenAI.
+	PrefixText string `json:"prefix_text,omitempty"`
+
+	// [optional] Remove the imgflip.com watermark. Only available to premium
+	// accounts.
+	NoWatermark bool `json:"no_watermark,omitempty"`
+}
+
+// AppendForm appends the application/x-www-form-urlencoded encoding of ar to
+// dst and returns the extended buffer, like CaptionRequest.AppendForm.
+func (ar AIMemeRequest) AppendForm(dst []byte) []byte {
+	w := newFormWriter(dst)
+	if ar.Model != "" {
+		w.string("model", string(ar.Model))
+	}
+	if ar.NoWatermark {
+		w.bool("no_watermark", ar.NoWatermark)
+	}
+	if ar.Password != "" {
+		w.string("password", ar.Password)
+	}
+	if ar.PrefixText != "" {
+		w.string("prefix_text", ar.PrefixText)
+	}
+	if ar.TemplateID != "" {
+		w.string("template_id", ar.TemplateID)
+	}
+	if ar.Username != "" {
+		w.string("username", ar.Username)
+	}
+	return w.buf
+}
+
+func (ar AIMemeRequest) CreateHTTPFormBody() (url.Values, error) {
+	return url.ParseQuery(string(ar.AppendForm(nil)))
+}
+
+// AIMeme wraps the ai_meme endpoint using DefaultClient. On success, the
+// CaptionResponse also contains the generated texts and the chosen template.
+// It has the same error semantics as CaptionImage.
+func AIMeme(req *AIMemeRequest) (CaptionResponse, error) {
+	return DefaultClient.AIMeme(req)
+}
+
+// AIMemeContext is like AIMeme, but the request is bound to ctx.
+func AIMemeContext(ctx context.Context, req *AIMemeRequest) (CaptionResponse, error) {
+	return DefaultClient.AIMemeContext(ctx, req)
+}
+
+// AIMeme wraps the ai_meme endpoint, see the package-level AIMeme.
+// If req specifies neither a Username nor a Password, the Client's credentials are used.
+func (c *Client) AIMeme(req *AIMemeRequest) (CaptionResponse, error) {
+	return c.AIMemeContext(context.Background(), req)
+}
+
+// AIMemeContext is like AIMeme, but the request is bound to ctx.
+func (c *Client) AIMemeContext(ctx context.Context, req *AIMemeRequest) (CaptionResponse, error) {
+	if req == nil {
+		return CaptionResponse{Success: false, ErrorMsg: ErrNilRequest.Error()}, ErrNilRequest
+	}
+
+	withAuth := *req
+	withAuth.Username, withAuth.Password = c.credentials(req.Username, req.Password)
+
+	err := withAuth.Validate()
+	if err != nil {
+		return CaptionResponse{Success: false, ErrorMsg: fmt.Sprint(err)}, err
+	}
+
+	return c.caption(ctx, aiMemePath, withAuth.AppendForm(nil))
+}
@@ -0,0 +1,79 @@
+package imgflipgo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func TestAIMeme(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ai_meme" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.PostFormValue("model") != string(imgflipgo.AIModelClassic) {
+			t.Errorf("unexpected model %q", r.PostFormValue("model"))
+		}
+		fmt.Fprintf(w, `{"success":true,"data":{"url":"https://i.imgflip.com/ai.jpg","page_url":"https://imgflip.com/i/ai","template_id":%s,"texts":["%s","tests in prod"]}}`,
+			r.PostFormValue("template_id"), r.PostFormValue("prefix_text"))
+	}))
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL), imgflipgo.WithCredentials("user", "pass"))
+	resp, err := client.AIMeme(&imgflipgo.AIMemeRequest{
+		Model:      imgflipgo.AIModelClassic,
+		TemplateID: testTemplateID,
+		PrefixText: "no tests",
+	})
+	expectSuccess(t, resp, err)
+	if resp.Data.TemplateID != testTemplateID {
+		t.Errorf("expected template ID %s, got %s", testTemplateID, resp.Data.TemplateID)
+	}
+	if len(resp.Data.Texts) != 2 || resp.Data.Texts[0] != "no tests" {
+		t.Errorf("unexpected texts %v", resp.Data.Texts)
+	}
+}
+
+func TestAIMemePrefixTooLong(t *testing.T) {
+	resp, err := imgflipgo.AIMeme(&imgflipgo.AIMemeRequest{
+		PrefixText: strings.Repeat("a", imgflipgo.MaxAIMemePrefixLen+1),
+	})
+	expectFailure(t, resp, err)
+}
+
+func TestAIMemePrefixCountsCharacters(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	resp, err := client.AIMeme(&imgflipgo.AIMemeRequest{
+		PrefixText: strings.Repeat("é", imgflipgo.MaxAIMemePrefixLen),
+	})
+	expectSuccess(t, resp, err)
+
+	resp, err = client.AIMeme(&imgflipgo.AIMemeRequest{
+		PrefixText: strings.Repeat("é", imgflipgo.MaxAIMemePrefixLen+1),
+	})
+	expectFailure(t, resp, err)
+	var validationErr *imgflipgo.ValidationError
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) || !errors.As(err, &validationErr) {
+		t.Errorf("expected a *ValidationError, got %v", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("expected the invalid request not to be sent, but the server saw %d requests", n)
+	}
+}
+
+func TestAIMemeNilRequest(t *testing.T) {
+	resp, err := imgflipgo.NewClient(imgflipgo.WithCredentials("user", "pass")).AIMeme(nil)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrNilRequest) {
+		t.Errorf("expected ErrNilRequest, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package imgflipgo
+
+import (
+	"context"
+	"net/url"
+)
+
+const AutoMemeEndpoint = DefaultBaseURL + "/" + autoMemePath
+
+type AutoMemeRequest struct {
+	// Username of a valid imgflip account. The automeme endpoint requires
+	// authentication.
+	Username string `json:"username,omitempty"`
+
+	// Password for the imgflip account.
+	Password string `json:"password,omitempty"`
+
+	// Free text that imgflip will pick a template for and caption with.
+	Text string `json:"text,omitempty"`
+
+	// [optional] Remove the imgflip.com watermark. Only available to premium
+	// accounts.
+	NoWatermark bool `json:"no_watermark,omitempty"`
+}
+
+// AutoMemeOption sets an optional AutoMemeRequest parameter.
+type AutoMemeOption func(*AutoMemeRequest)
+
+// AutoMemeCredentials sets the imgflip account used for the request. If not
+// provided, the Client's credentials are used.
+func AutoMemeCredentials(username, password string) AutoMemeOption {
+	return func(ar *AutoMemeRequest) {
+		ar.Username = username
+		ar.Password = password
+	}
+}
+
+// AutoMemeNoWatermark sets whether the imgflip.com watermark should be removed.
+func AutoMemeNoWatermark(noWatermark bool) AutoMemeOption {
+	return func(ar *AutoMemeRequest) {
+		ar.NoWatermark = noWatermark
+	}
+}
+
+// AppendForm appends the application/x-www-form-urlencoded encoding of ar to
+// dst and returns the extended buffer, like CaptionRequest.AppendForm.
+func (ar AutoMemeRequest) AppendForm(dst []byte) []byte {
+	w := newFormWriter(dst)
+	if ar.NoWatermark {
+		w.bool("no_watermark", ar.NoWatermark)
+	}
+	if ar.Password != "" {
+		w.string("password", ar.Password)
+	}
+	w.string("text", ar.Text)
+	if ar.Username != "" {
+		w.string("username", ar.Username)
+	}
+	return w.buf
+}
+
+func (ar AutoMemeRequest) CreateHTTPFormBody() (url.Values, error) {
+	return url.ParseQuery(string(ar.AppendForm(nil)))
+}
+
+// AutoMeme wraps the automeme endpoint using DefaultClient. imgflip picks a
+// template that suits text and captions it. It has the same error semantics as
+// CaptionImage.
+func AutoMeme(text string, opts ...AutoMemeOption) (CaptionResponse, error) {
+	return DefaultClient.AutoMeme(text, opts...)
+}
+
+// AutoMemeContext is like AutoMeme, but the request is bound to ctx.
+func AutoMemeContext(ctx context.Context, text string, opts ...AutoMemeOption) (CaptionResponse, error) {
+	return DefaultClient.AutoMemeContext(ctx, text, opts...)
+}
+
+// AutoMeme wraps the automeme endpoint, see the package-level AutoMeme.
+func (c *Client) AutoMeme(text string, opts ...AutoMemeOption) (CaptionResponse, error) {
+	return c.AutoMemeContext(context.Background(), text, opts...)
+}
+
+// AutoMemeContext is like AutoMeme, but the request is bound to ctx.
+func (c *Client) AutoMemeContext(ctx context.Context, text string, opts ...AutoMemeOption) (CaptionResponse, error) {
+	req := AutoMemeRequest{Text: text}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	req.Username, req.Password = c.credentials(req.Username, req.Password)
+
+	return c.caption(ctx, autoMemePath, req.AppendForm(nil))
+}
@@ -0,0 +1,39 @@
+package imgflipgo_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func TestAutoMeme(t *testing.T) {
+	const text = "one does not simply write tests"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/automeme" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.PostFormValue("username") != "user" || r.PostFormValue("password") != "pass" {
+			fmt.Fprint(w, `{"success":false,"error_message":"Invalid username/password"}`)
+			return
+		}
+		if r.PostFormValue("text") != text {
+			t.Errorf("expected text %q, got %q", text, r.PostFormValue("text"))
+		}
+		if r.PostFormValue("no_watermark") == "" {
+			t.Error("expected no_watermark to be set")
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"url":"https://i.imgflip.com/auto.jpg","page_url":"https://imgflip.com/i/auto"}}`)
+	}))
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL))
+
+	resp, err := client.AutoMeme(text, imgflipgo.AutoMemeCredentials("user", "pass"), imgflipgo.AutoMemeNoWatermark(true))
+	expectSuccess(t, resp, err)
+
+	resp, err = client.AutoMeme(text)
+	expectFailure(t, resp, err)
+}
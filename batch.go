@@ -0,0 +1,119 @@
+package imgflipgo
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the number of requests a batch sends at once unless
+// configured otherwise with BatchConcurrency.
+const DefaultBatchConcurrency = 4
+
+// BatchResult is the outcome of one request in a batch.
+type BatchResult struct {
+	// Index of the request in the batch.
+	Index int
+
+	// Response and Err are as returned by CaptionImage for the request.
+	Response CaptionResponse
+	Err      error
+}
+
+// batchConfig holds the settings applied by BatchOptions.
+type batchConfig struct {
+	concurrency int
+}
+
+// BatchOption sets an optional parameter of a batch.
+type BatchOption func(*batchConfig)
+
+// BatchConcurrency sets the number of requests a batch sends at once. Values
+// less than 1 are treated as 1.
+func BatchConcurrency(n int) BatchOption {
+	return func(cfg *batchConfig) {
+		if n < 1 {
+			n = 1
+		}
+		cfg.concurrency = n
+	}
+}
+
+// CaptionBatch captions every request in reqs using DefaultClient. See
+// Client.CaptionBatch.
+func CaptionBatch(ctx context.Context, reqs []CaptionRequest, opts ...BatchOption) []BatchResult {
+	return DefaultClient.CaptionBatch(ctx, reqs, opts...)
+}
+
+// CaptionBatchStream is like CaptionBatch, but streams results using
+// DefaultClient. See Client.CaptionBatchStream.
+func CaptionBatchStream(ctx context.Context, reqs []CaptionRequest, opts ...BatchOption) <-chan BatchResult {
+	return DefaultClient.CaptionBatchStream(ctx, reqs, opts...)
+}
+
+// CaptionBatch captions every request in reqs with CaptionImageContext, sending
+// up to DefaultBatchConcurrency requests at once, and returns one result per
+// request in the same order as reqs. A failed request does not stop the rest of
+// the batch. Requests still wait for the Client's rate limiters and are retried
+// according to its RetryPolicy, so a shared RateLimiter bounds the batch's
+// request rate no matter its concurrency. If ctx is done, requests that have not
+// completed fail with an error wrapping ctx.Err().
+func (c *Client) CaptionBatch(ctx context.Context, reqs []CaptionRequest, opts ...BatchOption) []BatchResult {
+	results := make([]BatchResult, len(reqs))
+	c.runBatch(ctx, reqs, opts, func(result BatchResult) {
+		results[result.Index] = result
+	})
+	return results
+}
+
+// CaptionBatchStream is like CaptionBatch, but returns a channel that receives
+// each result as soon as its request completes, so results arrive out of order.
+// The channel is closed once every request has completed. It is buffered to hold
+// every result, so the batch runs to completion even if the caller stops
+// receiving.
+func (c *Client) CaptionBatchStream(ctx context.Context, reqs []CaptionRequest, opts ...BatchOption) <-chan BatchResult {
+	results := make(chan BatchResult, len(reqs))
+	go func() {
+		defer close(results)
+		c.runBatch(ctx, reqs, opts, func(result BatchResult) {
+			results <- result
+		})
+	}()
+	return results
+}
+
+// runBatch captions reqs with a pool of workers, calling report with each
+// result from the worker that produced it.
+func (c *Client) runBatch(ctx context.Context, reqs []CaptionRequest, opts []BatchOption, report func(BatchResult)) {
+	cfg := batchConfig{concurrency: DefaultBatchConcurrency}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.concurrency > len(reqs) {
+		cfg.concurrency = len(reqs)
+	}
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < cfg.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				result := BatchResult{Index: i}
+				if err := ctx.Err(); err != nil {
+					result.Err = contextError(ctx, captionImagePath, err)
+					result.Response = CaptionResponse{Success: false, ErrorMsg: result.Err.Error()}
+				} else {
+					result.Response, result.Err = c.CaptionImageContext(ctx, &reqs[i])
+				}
+				report(result)
+			}
+		}()
+	}
+
+	for i := range reqs {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
@@ -0,0 +1,165 @@
+package imgflipgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+// concurrencyTransport records the most requests it has seen in flight at once.
+type concurrencyTransport struct {
+	base http.RoundTripper
+
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (t *concurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.inFlight++
+	if t.inFlight > t.max {
+		t.max = t.inFlight
+	}
+	t.mu.Unlock()
+
+	// Give other workers a chance to start their requests.
+	time.Sleep(5 * time.Millisecond)
+	resp, err := t.base.RoundTrip(req)
+
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+	return resp, err
+}
+
+func batchRequests(n int) []imgflipgo.CaptionRequest {
+	reqs := make([]imgflipgo.CaptionRequest, n)
+	for i := range reqs {
+		reqs[i].TemplateID = imgflipgotest.Memes[i%len(imgflipgotest.Memes)].ID
+		reqs[i].SetTopText(fmt.Sprintf("request %d", i))
+	}
+	return reqs
+}
+
+func TestCaptionBatch(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	transport := &concurrencyTransport{base: srv.Client().Transport}
+	client := srv.NewClient(imgflipgo.WithHTTPClient(&http.Client{Transport: transport}))
+
+	reqs := batchRequests(12)
+	reqs[5].TemplateID = "does-not-exist"
+
+	results := client.CaptionBatch(context.Background(), reqs, imgflipgo.BatchConcurrency(3))
+	if len(results) != len(reqs) {
+		t.Fatalf("expected %d results, got %d", len(reqs), len(results))
+	}
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("result %d: expected index %d, got %d", i, i, result.Index)
+		}
+		if i == 5 {
+			if !errors.Is(result.Err, imgflipgo.ErrTemplateNotFound) {
+				t.Errorf("result %d: expected ErrTemplateNotFound, got %v", i, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil || result.Response.Data.URL == "" {
+			t.Errorf("result %d: unexpected failure %v", i, result.Err)
+		}
+	}
+
+	// Check each request was sent with the right text, regardless of order.
+	sent := map[string]bool{}
+	for _, req := range srv.Requests() {
+		sent[req.Form.Get("text0")] = true
+	}
+	for i := range reqs {
+		if !sent[fmt.Sprintf("request %d", i)] {
+			t.Errorf("request %d was not sent", i)
+		}
+	}
+
+	if transport.max > 3 {
+		t.Errorf("expected at most 3 requests in flight, saw %d", transport.max)
+	}
+	if transport.max < 2 {
+		t.Errorf("expected requests to run concurrently, saw at most %d in flight", transport.max)
+	}
+}
+
+func TestCaptionBatchStream(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	reqs := batchRequests(10)
+	seen := map[int]bool{}
+	for result := range client.CaptionBatchStream(context.Background(), reqs) {
+		if seen[result.Index] {
+			t.Errorf("result %d received twice", result.Index)
+		}
+		seen[result.Index] = true
+		if result.Err != nil {
+			t.Errorf("result %d: unexpected failure %v", result.Index, result.Err)
+		}
+	}
+	if len(seen) != len(reqs) {
+		t.Errorf("expected %d results, got %d", len(reqs), len(seen))
+	}
+}
+
+func TestCaptionBatchRateLimited(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	limiter := imgflipgo.NewRateLimiter(time.Hour, 2).SetFailFast(true)
+	client := srv.NewClient(imgflipgo.WithRateLimiter(limiter))
+
+	succeeded, limited := 0, 0
+	for _, result := range client.CaptionBatch(context.Background(), batchRequests(6)) {
+		switch {
+		case result.Err == nil:
+			succeeded++
+		case errors.Is(result.Err, imgflipgo.ErrRateLimited):
+			limited++
+		default:
+			t.Errorf("result %d: unexpected error %v", result.Index, result.Err)
+		}
+	}
+	if succeeded != 2 || limited != 4 {
+		t.Errorf("expected 2 requests to succeed and 4 to be rate limited, got %d and %d", succeeded, limited)
+	}
+}
+
+func TestCaptionBatchCanceled(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := client.CaptionBatch(ctx, batchRequests(5))
+	for _, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("result %d: expected context.Canceled, got %v", result.Index, result.Err)
+		}
+		if result.Response.ErrorMsg != result.Err.Error() {
+			t.Errorf("result %d: expected ErrorMsg to match the error", result.Index)
+		}
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("expected no requests to be sent, got %d", n)
+	}
+
+	if results := client.CaptionBatch(context.Background(), nil); len(results) != 0 {
+		t.Errorf("expected no results for an empty batch, got %d", len(results))
+	}
+}
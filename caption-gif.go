@@ -0,0 +1,84 @@
+package imgflipgo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+const CaptionGifEndpoint = DefaultBaseURL + "/" + captionGifPath
+
+type CaptionGifRequest struct {
+	// A gif template ID, e.g. as returned by the get_memes response for a
+	// template with an animated URL.
+	TemplateID string `json:"template_id,omitempty"`
+
+	// Username of a valid imgflip account. This is used to track where API
+	// requests are coming from.
+	Username string `json:"username,omitempty"`
+
+	// Password for the imgflip account.
+	Password string `json:"password,omitempty"`
+
+	// The text boxes to draw on the gif. Unlike caption_image, caption_gif has no
+	// top/bottom text shorthand, so at least one TextBox is required. The
+	// StartMs and EndMs fields of each TextBox control when it is displayed.
+	TextBoxes []TextBox `json:"boxes,omitempty"`
+}
+
+// AppendForm appends the application/x-www-form-urlencoded encoding of cgr to
+// dst and returns the extended buffer, like CaptionRequest.AppendForm.
+func (cgr CaptionGifRequest) AppendForm(dst []byte) []byte {
+	w := newFormWriter(dst)
+	w.textBoxes(cgr.TextBoxes)
+	if cgr.Password != "" {
+		w.string("password", cgr.Password)
+	}
+	if cgr.TemplateID != "" {
+		w.string("template_id", cgr.TemplateID)
+	}
+	if cgr.Username != "" {
+		w.string("username", cgr.Username)
+	}
+	return w.buf
+}
+
+func (cgr CaptionGifRequest) CreateHTTPFormBody() (url.Values, error) {
+	return url.ParseQuery(string(cgr.AppendForm(nil)))
+}
+
+// CaptionGif wraps the caption_gif endpoint using DefaultClient. It has the same
+// error semantics as CaptionImage: if the API returns an error, it will be reflected
+// in both CaptionResponse.ErrorMsg and in the returned Go error.
+func CaptionGif(req *CaptionGifRequest) (CaptionResponse, error) {
+	return DefaultClient.CaptionGif(req)
+}
+
+// CaptionGifContext is like CaptionGif, but the request is bound to ctx.
+func CaptionGifContext(ctx context.Context, req *CaptionGifRequest) (CaptionResponse, error) {
+	return DefaultClient.CaptionGifContext(ctx, req)
+}
+
+// CaptionGif wraps the caption_gif endpoint, see the package-level CaptionGif.
+// If req specifies neither a Username nor a Password, the Client's credentials are used.
+// The request is checked with Validate before anything is sent.
+func (c *Client) CaptionGif(req *CaptionGifRequest) (CaptionResponse, error) {
+	return c.CaptionGifContext(context.Background(), req)
+}
+
+// CaptionGifContext is like CaptionGif, but the request is bound to ctx.
+func (c *Client) CaptionGifContext(ctx context.Context, req *CaptionGifRequest) (CaptionResponse, error) {
+	if req == nil {
+		return CaptionResponse{Success: false, ErrorMsg: ErrNilRequest.Error()}, ErrNilRequest
+	}
+
+	withAuth := *req
+	withAuth.Username, withAuth.Password = c.credentials(req.Username, req.Password)
+
+	err := withAuth.Validate()
+	if err != nil {
+		return CaptionResponse{Success: false, ErrorMsg: fmt.Sprint(err)}, err
+	}
+
+	return c.caption(ctx, captionGifPath, withAuth.AppendForm(nil))
+}
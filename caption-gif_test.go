@@ -0,0 +1,73 @@
+package imgflipgo_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func TestCaptionGifForm(t *testing.T) {
+	req := imgflipgo.CaptionGifRequest{
+		TemplateID: "1234",
+		Username:   "user",
+		Password:   "pass",
+		TextBoxes: []imgflipgo.TextBox{
+			*(&imgflipgo.TextBox{Text: "first"}).SetEndMs(1500),
+			*(&imgflipgo.TextBox{Text: "second"}).SetStartMs(1500).SetColor(0xFFA500),
+		},
+	}
+	form, err := req.CreateHTTPFormBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"template_id":        "1234",
+		"username":           "user",
+		"password":           "pass",
+		"boxes[0][text]":     "first",
+		"boxes[0][end_ms]":   "1500",
+		"boxes[1][text]":     "second",
+		"boxes[1][start_ms]": "1500",
+		"boxes[1][color]":    "#ffa500",
+	}
+	for k, v := range expected {
+		if got := form.Get(k); got != v {
+			t.Errorf("expected %s=%q, got %q", k, v, got)
+		}
+	}
+	if len(form) != len(expected) {
+		t.Errorf("expected %d form fields, got %d: %v", len(expected), len(form), form)
+	}
+}
+
+func TestCaptionGif(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/caption_gif" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.PostFormValue("boxes[0][text]") == "" {
+			fmt.Fprint(w, `{"success":false,"error_message":"No texts specified."}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"url":"https://i.imgflip.com/test.gif","page_url":"https://imgflip.com/gif/test"}}`)
+	}))
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL), imgflipgo.WithCredentials("user", "pass"))
+
+	resp, err := client.CaptionGif(&imgflipgo.CaptionGifRequest{
+		TemplateID: "1234",
+		TextBoxes:  []imgflipgo.TextBox{{Text: "animated"}},
+	})
+	expectSuccess(t, resp, err)
+
+	resp, err = client.CaptionGif(&imgflipgo.CaptionGifRequest{TemplateID: "1234"})
+	expectFailure(t, resp, err)
+
+	resp, err = client.CaptionGif(nil)
+	expectFailure(t, resp, err)
+}
@@ -1,18 +1,14 @@
 package imgflipgo
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 	"reflect"
-
-	"github.com/gorilla/schema"
 )
 
-const CaptionMemeEndpoint = "https://api.imgflip.com/caption_image"
+const CaptionMemeEndpoint = DefaultBaseURL + "/" + captionImagePath
 
 type Font string
 
@@ -39,11 +35,19 @@ type TextBox struct {
 	// If specified, must also specify X, Y, Width
 	Height *uint `json:"height,omitempty"`
 
-	// [optional] Hex color for Text
-	Color *uint `json:"color,omitempty"`
+	// [optional] Color for Text
+	Color *Color `json:"color,omitempty"`
+
+	// [optional] Color for Text outline
+	OutlineColor *Color `json:"outline_color,omitempty"`
 
-	// [optional] Hex color for Text outline
-	OutlineColor *uint `json:"outline_color,omitempty"`
+	// [optional] (caption_gif only) Time in milliseconds, from the start of the
+	// animation, at which Text appears. Defaults to the first frame.
+	StartMs *uint `json:"start_ms,omitempty"`
+
+	// [optional] (caption_gif only) Time in milliseconds, from the start of the
+	// animation, at which Text disappears. Defaults to the last frame.
+	EndMs *uint `json:"end_ms,omitempty"`
 }
 
 func (t *TextBox) SetX(x uint) *TextBox {
@@ -62,32 +66,75 @@ func (t *TextBox) SetHeight(height uint) *TextBox {
 	t.Height = &height
 	return t
 }
-func (t *TextBox) SetColor(color uint) *TextBox {
+func (t *TextBox) SetColor(color Color) *TextBox {
 	t.Color = &color
 	return t
 }
-func (t *TextBox) SetOutlineColor(outlineColor uint) *TextBox {
+func (t *TextBox) SetOutlineColor(outlineColor Color) *TextBox {
 	t.OutlineColor = &outlineColor
 	return t
 }
+func (t *TextBox) SetStartMs(startMs uint) *TextBox {
+	t.StartMs = &startMs
+	return t
+}
+func (t *TextBox) SetEndMs(endMs uint) *TextBox {
+	t.EndMs = &endMs
+	return t
+}
+
+// TextJSONTag returns the JSON name of the Text field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) TextJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "Text")
 }
+
+// XJSONTag returns the JSON name of the X field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) XJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "X")
 }
+
+// YJSONTag returns the JSON name of the Y field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) YJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "Y")
 }
+
+// WidthJSONTag returns the JSON name of the Width field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) WidthJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "Width")
 }
+
+// HeightJSONTag returns the JSON name of the Height field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) HeightJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "Height")
 }
+
+// ColorJSONTag returns the JSON name of the Color field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) ColorJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "Color")
 }
+
+// OutlineColorTextJSONTag returns the JSON name of the OutlineColor field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (t *TextBox) OutlineColorTextJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(t), "OutlineColor")
 }
@@ -104,28 +151,28 @@ type CaptionRequest struct {
 	// returned from the get_memes response should work for this parameter. For
 	// custom template uploads, the template ID can be found in the memegenerator
 	// URL, e.g. https://imgflip.com/memegenerator/14859329/Charlie-Sheen-DERP.
-	TemplateID string `schema:"template_id,omitempty" json:"template_id,omitempty"`
+	TemplateID string `json:"template_id,omitempty"`
 
 	// Username of a valid imgflip account. This is used to track where API
 	// requests are coming from.
-	Username string `schema:"username,omitempty" json:"username,omitempty"`
+	Username string `json:"username,omitempty"`
 
 	// Password for the imgflip account.
-	Password string `schema:"password,omitempty" json:"password,omitempty"`
+	Password string `json:"password,omitempty"`
 
 	// Top text for the meme. Do not use this parameter if you are using the
 	// boxes parameter below.
-	TopText *string `schema:"text0,omitempty" json:"text0,omitempty"`
+	TopText *string `json:"text0,omitempty"`
 
 	// Bottom text for the meme. Do not use this parameter if you are using the
 	// boxes parameter below.
-	BottomText *string `schema:"text1,omitempty" json:"text1,omitempty"`
+	BottomText *string `json:"text1,omitempty"`
 
 	// [optional] The font family to use for the text
-	Font *Font `schema:"font,omitempty" json:"font,omitempty"`
+	Font *Font `json:"font,omitempty"`
 
 	// [optional] Maximum font size in pixels. Defaults to 50px.
-	MaxFontSizePx *uint `schema:"max_font_size,omitempty" json:"max_font_size,omitempty"`
+	MaxFontSizePx *uint `json:"max_font_size,omitempty"`
 
 	// [optional] For creating memes with more than two text boxes, or for further
 	// customization. If TextBoxes is specified, TopText and BototmText will be ignored,
@@ -134,7 +181,7 @@ type CaptionRequest struct {
 	// The API is currently limited to 20 text boxes per image. The first TextBox in
 	// the list may be left empty so that the second box will automatically be used
 	// as bottom text.
-	TextBoxes []TextBox `schema:"-" json:"boxes,omitempty"`
+	TextBoxes []TextBox `json:"boxes,omitempty"`
 }
 
 func (cr *CaptionRequest) SetTopText(topText string) *CaptionRequest {
@@ -153,128 +200,119 @@ func (cr *CaptionRequest) SetMaxFontSize(maxFontSizePx uint) *CaptionRequest {
 	cr.MaxFontSizePx = &maxFontSizePx
 	return cr
 }
+
+// TemplateIDJSONTag returns the JSON name of the TemplateID field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) TemplateIDJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "TemplateID")
 }
+
+// UsernameJSONTag returns the JSON name of the Username field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) UsernameJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "Username")
 }
+
+// PasswordJSONTag returns the JSON name of the Password field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) PasswordJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "Password")
 }
+
+// TopTextJSONTag returns the JSON name of the TopText field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) TopTextJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "TopText")
 }
+
+// BottomTextJSONTag returns the JSON name of the BottomText field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) BottomTextJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "BottomText")
 }
+
+// FontJSONTag returns the JSON name of the Font field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) FontJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "Font")
 }
+
+// MaxFontSizePxJSONTag returns the JSON name of the MaxFontSizePx field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) MaxFontSizePxJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "MaxFontSizePx")
 }
+
+// TextBoxesJSONTag returns the JSON name of the TextBoxes field.
+//
+// Deprecated: Field names never change, and requests are encoded by
+// AppendForm.
 func (cr *CaptionRequest) TextBoxesJSONTag() (string, error) {
 	return getStructFieldJSONTag(reflect.TypeOf(cr), "TextBoxes")
 }
 
-var encoder schema.Encoder
-
-func init() {
-	encoder = *schema.NewEncoder()
-	encoder.RegisterEncoder(CaptionRequest{}.Font, func(value reflect.Value) string {
-		return value.Elem().String()
-	})
-}
-
-func (cr CaptionRequest) CreateHTTPFormBody() (url.Values, error) {
-	form := url.Values{}
-	err := encoder.Encode(cr, form)
-	if err != nil {
-		return form, err
+// AppendForm appends the application/x-www-form-urlencoded encoding of cr to
+// dst and returns the extended buffer. The result is identical to encoding
+// CreateHTTPFormBody's url.Values, but is written directly, without reflection
+// or any allocations beyond growing dst.
+func (cr CaptionRequest) AppendForm(dst []byte) []byte {
+	w := newFormWriter(dst)
+	w.textBoxes(cr.TextBoxes)
+	if cr.Font != nil {
+		w.string("font", string(*cr.Font))
 	}
-
-	// Have to encode TextBoxes manually because gorilla/schema doesn't handle
-	// slices of custom structs (or if it does I'm too dumb to figure out how).
-	textBoxesJSONTag, err := cr.TextBoxesJSONTag()
-	if err != nil {
-		return form, err
+	if cr.MaxFontSizePx != nil {
+		w.uint("max_font_size", *cr.MaxFontSizePx)
 	}
-
-	var textJSONTag string
-	var xJSONTag string
-	var yJSONTag string
-	var widthJSONTag string
-	var heightJSONTag string
-	var colorJSONTag string
-	var outlineColorJSONTag string
-	for i := range cr.TextBoxes {
-		if i == 0 {
-			textJSONTag, err = cr.TextBoxes[i].TextJSONTag()
-			if err != nil {
-				return form, err
-			}
-			xJSONTag, err = cr.TextBoxes[i].XJSONTag()
-			if err != nil {
-				return form, err
-			}
-			yJSONTag, err = cr.TextBoxes[i].YJSONTag()
-			if err != nil {
-				return form, err
-			}
-			widthJSONTag, err = cr.TextBoxes[i].WidthJSONTag()
-			if err != nil {
-				return form, err
-			}
-			heightJSONTag, err = cr.TextBoxes[i].HeightJSONTag()
-			if err != nil {
-				return form, err
-			}
-			colorJSONTag, err = cr.TextBoxes[i].ColorJSONTag()
-			if err != nil {
-				return form, err
-			}
-			outlineColorJSONTag, err = cr.TextBoxes[i].OutlineColorTextJSONTag()
-			if err != nil {
-				return form, err
-			}
-		}
-
-		form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, textJSONTag), cr.TextBoxes[i].Text)
-
-		if cr.TextBoxes[i].X != nil {
-			form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, xJSONTag), fmt.Sprint(*cr.TextBoxes[i].X))
-		}
-
-		if cr.TextBoxes[i].Y != nil {
-			form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, yJSONTag), fmt.Sprint(*cr.TextBoxes[i].Y))
-		}
-
-		if cr.TextBoxes[i].Width != nil {
-			form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, widthJSONTag), fmt.Sprint(*cr.TextBoxes[i].Width))
-		}
-
-		if cr.TextBoxes[i].Height != nil {
-			form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, heightJSONTag), fmt.Sprint(*cr.TextBoxes[i].Height))
-		}
-
-		if cr.TextBoxes[i].Color != nil {
-			form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, colorJSONTag), fmt.Sprintf("#%06x", *cr.TextBoxes[i].Color))
-		}
-
-		if cr.TextBoxes[i].OutlineColor != nil {
-			form.Add(fmt.Sprintf("%s[%d][%s]", textBoxesJSONTag, i, outlineColorJSONTag), fmt.Sprintf("#%06x", *cr.TextBoxes[i].OutlineColor))
-		}
+	if cr.Password != "" {
+		w.string("password", cr.Password)
+	}
+	if cr.TemplateID != "" {
+		w.string("template_id", cr.TemplateID)
+	}
+	if cr.TopText != nil {
+		w.string("text0", *cr.TopText)
+	}
+	if cr.BottomText != nil {
+		w.string("text1", *cr.BottomText)
+	}
+	if cr.Username != "" {
+		w.string("username", cr.Username)
 	}
+	return w.buf
+}
 
-	return form, nil
+// CreateHTTPFormBody returns the form sent to the caption_image endpoint for cr.
+// Prefer AppendForm where the encoded form is all that's needed.
+func (cr CaptionRequest) CreateHTTPFormBody() (url.Values, error) {
+	return url.ParseQuery(string(cr.AppendForm(nil)))
 }
 
 type CaptionResponse struct {
 	Success bool `json:"success,omitempty"`
 	Data    struct {
-		URL     string
-		PageURL string
+		URL     string `json:"url,omitempty"`
+		PageURL string `json:"page_url,omitempty"`
+
+		// TemplateID of the captioned template. Only populated by AIMeme.
+		TemplateID json.Number `json:"template_id,omitempty"`
+
+		// Texts that were generated for the meme. Only populated by AIMeme.
+		Texts []string `json:"texts,omitempty"`
 	} `json:"data,omitempty"`
 
 	ErrorMsg string `json:"error_message,omitempty"`
@@ -285,39 +323,65 @@ type CaptionResponse struct {
 // process. The errors can come originate in Go or be from the API, depending on where
 // the failure occurred. This was done so that the caller does not have to check both
 // the returned error value, AND CaptionResponse.Success. If the API returns an error,
-// it will be reflected in both CaptionResponse.ErrorMsg and in the returned Go error.
+// it will be reflected in both CaptionResponse.ErrorMsg and in the returned Go error,
+// which will be an *APIError. Use errors.Is with ErrInvalidCredentials or
+// ErrTemplateNotFound to check for common causes.
+//
+// CaptionImage uses DefaultClient. See Client.CaptionImage.
 func CaptionImage(req *CaptionRequest) (CaptionResponse, error) {
+	return DefaultClient.CaptionImage(req)
+}
+
+// CaptionImageContext is like CaptionImage, but the request is bound to ctx.
+func CaptionImageContext(ctx context.Context, req *CaptionRequest) (CaptionResponse, error) {
+	return DefaultClient.CaptionImageContext(ctx, req)
+}
+
+// CaptionImage wraps the caption_image endpoint, see the package-level CaptionImage.
+// If req specifies neither a Username nor a Password, the Client's credentials are used.
+// The request is checked with Validate before anything is sent, and any problems
+// are returned as a *ValidationError.
+func (c *Client) CaptionImage(req *CaptionRequest) (CaptionResponse, error) {
+	return c.CaptionImageContext(context.Background(), req)
+}
+
+// CaptionImageContext is like CaptionImage, but the request is bound to ctx. If ctx
+// is canceled or its deadline passes before the request completes, the returned
+// error wraps ctx.Err().
+func (c *Client) CaptionImageContext(ctx context.Context, req *CaptionRequest) (CaptionResponse, error) {
 	if req == nil {
-		return CaptionResponse{Success: false, ErrorMsg: "nil request provided"}, errors.New("nil request provided")
+		return CaptionResponse{Success: false, ErrorMsg: ErrNilRequest.Error()}, ErrNilRequest
 	}
 
-	form, err := req.CreateHTTPFormBody()
-	if err != nil {
-		return CaptionResponse{Success: false, ErrorMsg: fmt.Sprint(err)}, err
-	}
+	withAuth := *req
+	withAuth.Username, withAuth.Password = c.credentials(req.Username, req.Password)
 
-	resp, err := http.PostForm(CaptionMemeEndpoint, form)
+	err := withAuth.Validate()
 	if err != nil {
 		return CaptionResponse{Success: false, ErrorMsg: fmt.Sprint(err)}, err
 	}
-	if resp == nil {
-		return CaptionResponse{Success: false, ErrorMsg: "nil response received"}, errors.New("nil response received")
-	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return CaptionResponse{Success: false, ErrorMsg: fmt.Sprint(err)}, err
-	}
+	return c.caption(ctx, captionImagePath, withAuth.AppendForm(nil))
+}
 
+// caption POSTs an encoded form to one of the captioning endpoints and decodes
+// the resulting CaptionResponse, with the error semantics documented on
+// CaptionImage.
+func (c *Client) caption(ctx context.Context, endpoint string, form []byte) (CaptionResponse, error) {
+	if form == nil {
+		// An empty form must still be POSTed.
+		form = []byte{}
+	}
 	captionResponse := CaptionResponse{}
-	err = json.Unmarshal(respBody, &captionResponse)
+	resp, err := c.call(ctx, endpoint, form, &captionResponse)
 	if err != nil {
 		return CaptionResponse{Success: false, ErrorMsg: fmt.Sprint(err)}, err
 	}
 
 	if !captionResponse.Success {
-		return captionResponse, errors.New(captionResponse.ErrorMsg)
+		apiErr := newAPIError(endpoint, resp, captionResponse.ErrorMsg)
+		captionResponse.ErrorMsg = apiErr.Error()
+		return captionResponse, apiErr
 	}
 
 	return captionResponse, nil
@@ -1,18 +1,22 @@
 package imgflipgo_test
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"testing"
 
 	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
 	"github.com/joho/godotenv"
 )
 
 func TestMain(m *testing.M) {
 	setup()
-	os.Exit(m.Run())
+	code := m.Run()
+	teardown()
+	os.Exit(code)
 }
 
 const (
@@ -25,10 +29,25 @@ var (
 	ImgflipAPIPassword string
 )
 
+var fakeServer *imgflipgotest.Server
+
 func setup() {
 	godotenv.Load()
 	ImgflipAPIUsername, _ = os.LookupEnv(ImgflipAPIUsernameEnv)
 	ImgflipAPIPassword, _ = os.LookupEnv(ImgflipAPIPasswordEnv)
+
+	// Without credentials the live API can't be tested, so test against a fake.
+	if ImgflipAPIUsername == "" || ImgflipAPIPassword == "" {
+		fakeServer = imgflipgotest.NewServer()
+		ImgflipAPIUsername, ImgflipAPIPassword = fakeServer.Credentials()
+		imgflipgo.DefaultClient = fakeServer.NewClient(imgflipgo.WithCredentials("", ""))
+	}
+}
+
+func teardown() {
+	if fakeServer != nil {
+		fakeServer.Close()
+	}
 }
 
 const testTemplateID = "181913649"
@@ -234,8 +253,10 @@ func TestCaptionImageTopBottomAndBoxes(t *testing.T) {
 			},
 		},
 	}).SetTopText("This text should not be displayed (top)").SetBottomText("This text should not be displayed (bottom)"))
-	expectSuccess(t, resp, err)
-	t.Logf("See test image at %s", resp.Data.URL)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatal("Expected a validation error, got", err)
+	}
 }
 
 func TestCaptionImageTopAndBox(t *testing.T) {
@@ -249,8 +270,10 @@ func TestCaptionImageTopAndBox(t *testing.T) {
 			},
 		},
 	}).SetTopText("This text should not be displayed (top)"))
-	expectSuccess(t, resp, err)
-	t.Logf("See test image at %s", resp.Data.URL)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatal("Expected a validation error, got", err)
+	}
 }
 
 func TestCaptionImageBottomAndBox(t *testing.T) {
@@ -265,8 +288,10 @@ func TestCaptionImageBottomAndBox(t *testing.T) {
 			},
 		},
 	}).SetBottomText("This text should not be displayed (bottom)"))
-	expectSuccess(t, resp, err)
-	t.Logf("See test image at %s", resp.Data.URL)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatal("Expected a validation error, got", err)
+	}
 }
 
 func TestCaptionImageTopAndBottomBox(t *testing.T) {
@@ -281,8 +306,10 @@ func TestCaptionImageTopAndBottomBox(t *testing.T) {
 			},
 		},
 	}).SetTopText("This text should not be displayed (top)"))
-	expectSuccess(t, resp, err)
-	t.Logf("See test image at %s", resp.Data.URL)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatal("Expected a validation error, got", err)
+	}
 }
 
 func TestCaptionImageBottomAndTopBox(t *testing.T) {
@@ -296,8 +323,10 @@ func TestCaptionImageBottomAndTopBox(t *testing.T) {
 			},
 		},
 	}).SetBottomText("This text should not be displayed (bottom)"))
-	expectSuccess(t, resp, err)
-	t.Logf("See test image at %s", resp.Data.URL)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatal("Expected a validation error, got", err)
+	}
 }
 
 func TestCaptionImageColor(t *testing.T) {
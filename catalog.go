@@ -0,0 +1,320 @@
+package imgflipgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// catalogRetryInterval is how long a MemeCatalog waits after a failed refresh
+// before trying again, unless its TTL is shorter.
+const catalogRetryInterval = time.Minute
+
+// CatalogSnapshot is the state of a MemeCatalog, as saved to and loaded from disk.
+type CatalogSnapshot struct {
+	Memes []Meme `json:"memes"`
+
+	// FetchedAt is when Memes were last confirmed to be current.
+	FetchedAt time.Time `json:"fetched_at"`
+
+	// ETag and LastModified are the validators returned with Memes, used to
+	// make conditional requests when refreshing.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// MemeCatalog caches the templates returned by the get_memes endpoint. Cached
+// memes are served until they are older than the catalog's TTL, after which
+// they are refreshed with a conditional request. A MemeCatalog is safe for
+// concurrent use.
+type MemeCatalog struct {
+	client *Client
+	ttl    time.Duration
+
+	mu       sync.RWMutex
+	snapshot CatalogSnapshot
+
+	// index is built from snapshot.Memes by Index, and discarded whenever
+	// they're replaced.
+	index *MemeIndex
+
+	// failedAt is when the last refresh failed with failErr, or zero if it
+	// succeeded.
+	failedAt time.Time
+	failErr  error
+
+	// refreshMu ensures only one refresh is in flight at a time.
+	refreshMu sync.Mutex
+
+	stopMu sync.Mutex
+	stop   context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMemeCatalog creates an empty MemeCatalog that fetches memes with client,
+// or DefaultClient if client is nil, and caches them for ttl.
+func NewMemeCatalog(client *Client, ttl time.Duration) *MemeCatalog {
+	if client == nil {
+		client = DefaultClient
+	}
+	return &MemeCatalog{client: client, ttl: ttl}
+}
+
+// Memes returns the cached memes, refreshing them first if they are older than
+// the catalog's TTL. If refreshing fails but memes were previously cached, the
+// stale memes are returned rather than an error, and no refresh is attempted
+// for a minute, or the TTL if that's shorter.
+func (mc *MemeCatalog) Memes(ctx context.Context) ([]Meme, error) {
+	snapshot := mc.Snapshot()
+	if len(snapshot.Memes) > 0 {
+		mc.mu.RLock()
+		failedAt := mc.failedAt
+		mc.mu.RUnlock()
+		retryInterval := catalogRetryInterval
+		if mc.ttl < retryInterval {
+			retryInterval = mc.ttl
+		}
+		if time.Since(snapshot.FetchedAt) < mc.ttl || time.Since(failedAt) < retryInterval {
+			return snapshot.Memes, nil
+		}
+	}
+
+	err := mc.Refresh(ctx)
+	snapshot = mc.Snapshot()
+	if err != nil && len(snapshot.Memes) == 0 {
+		return nil, err
+	}
+	return snapshot.Memes, nil
+}
+
+// Lookup returns the cached meme with the given template ID. It never makes a
+// request.
+func (mc *MemeCatalog) Lookup(templateID string) (Meme, bool) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	for _, meme := range mc.snapshot.Memes {
+		if meme.ID == templateID {
+			return meme, true
+		}
+	}
+	return Meme{}, false
+}
+
+// Index returns a MemeIndex over the memes returned by Memes. The index is
+// reused until the memes are replaced by a refresh or Load, so any aliases
+// added to it are lost then.
+func (mc *MemeCatalog) Index(ctx context.Context) (*MemeIndex, error) {
+	_, err := mc.Memes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.index == nil {
+		mc.index = NewMemeIndex(mc.snapshot.Memes)
+	}
+	return mc.index, nil
+}
+
+// Find returns the meme with templateIDOrName as its ID, or otherwise the best
+// match for it in Index. If nothing matches, the error matches
+// ErrTemplateNotFound.
+func (mc *MemeCatalog) Find(ctx context.Context, templateIDOrName string) (Meme, error) {
+	idx, err := mc.Index(ctx)
+	if err != nil {
+		return Meme{}, err
+	}
+	if meme, ok := mc.Lookup(templateIDOrName); ok {
+		return meme, nil
+	}
+	match, ok := idx.Best(templateIDOrName)
+	if !ok {
+		return Meme{}, fmt.Errorf("%w: nothing matches %q", ErrTemplateNotFound, templateIDOrName)
+	}
+	return match.Meme, nil
+}
+
+// Snapshot returns a copy of the catalog's current state.
+func (mc *MemeCatalog) Snapshot() CatalogSnapshot {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	snapshot := mc.snapshot
+	snapshot.Memes = append([]Meme(nil), mc.snapshot.Memes...)
+	return snapshot
+}
+
+// Refresh fetches the memes regardless of their age. If the catalog has
+// validators from a previous fetch, the request is conditional, and a 304 Not
+// Modified response simply renews the cached memes. Callers that wait for
+// another refresh to finish share its result rather than fetching again.
+func (mc *MemeCatalog) Refresh(ctx context.Context) (err error) {
+	start := time.Now()
+	mc.refreshMu.Lock()
+	defer mc.refreshMu.Unlock()
+
+	current := mc.Snapshot()
+	if current.FetchedAt.After(start) {
+		return nil
+	}
+	mc.mu.RLock()
+	failedAt, failErr := mc.failedAt, mc.failErr
+	mc.mu.RUnlock()
+	if failedAt.After(start) {
+		return failErr
+	}
+
+	defer func() {
+		mc.mu.Lock()
+		defer mc.mu.Unlock()
+		if err != nil {
+			mc.failedAt, mc.failErr = time.Now(), err
+		} else {
+			mc.failedAt, mc.failErr = time.Time{}, nil
+		}
+	}()
+	header := http.Header{}
+	if len(current.Memes) > 0 {
+		if current.ETag != "" {
+			header.Set("If-None-Match", current.ETag)
+		}
+		if current.LastModified != "" {
+			header.Set("If-Modified-Since", current.LastModified)
+		}
+	}
+
+	resp, err := mc.client.do(ctx, getMemesPath, nil, header)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		mc.mu.Lock()
+		mc.snapshot.FetchedAt = time.Now()
+		mc.mu.Unlock()
+		return nil
+	}
+
+	memesResp := MemesResponse{}
+	err = json.Unmarshal(resp.Body, &memesResp)
+	if err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return newAPIError(getMemesPath, resp, "")
+		}
+		return err
+	}
+	if !memesResp.Success {
+		return newAPIError(getMemesPath, resp, memesResp.ErrorMsg)
+	}
+
+	mc.mu.Lock()
+	mc.index = nil
+	mc.snapshot = CatalogSnapshot{
+		Memes:        memesResp.Data.Memes,
+		FetchedAt:    time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	mc.mu.Unlock()
+	return nil
+}
+
+// Start refreshes the catalog in the background every interval until Stop is
+// called. Errors are ignored, leaving the previously cached memes in place.
+// Calling Start on a catalog that is already refreshing has no effect.
+func (mc *MemeCatalog) Start(interval time.Duration) {
+	mc.stopMu.Lock()
+	defer mc.stopMu.Unlock()
+	if mc.stop != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mc.stop = cancel
+	mc.done = make(chan struct{})
+
+	go func(done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				mc.Refresh(ctx)
+			}
+		}
+	}(mc.done)
+}
+
+// Stop stops background refreshes started by Start, and waits for any refresh
+// in progress to be abandoned.
+func (mc *MemeCatalog) Stop() {
+	mc.stopMu.Lock()
+	defer mc.stopMu.Unlock()
+	if mc.stop == nil {
+		return
+	}
+	mc.stop()
+	<-mc.done
+	mc.stop = nil
+	mc.done = nil
+}
+
+// Save writes a snapshot of the catalog to path as JSON, so that it can be
+// restored with Load, e.g. to start without network access.
+func (mc *MemeCatalog) Save(path string) error {
+	data, err := json.MarshalIndent(mc.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a failed write can't corrupt an
+	// existing snapshot.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load replaces the catalog's contents with a snapshot written by Save. The
+// snapshot keeps its original fetch time, so memes older than the TTL are
+// still refreshed on the next call to Memes, falling back to the loaded memes
+// if that fails.
+func (mc *MemeCatalog) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	snapshot := CatalogSnapshot{}
+	err = json.Unmarshal(data, &snapshot)
+	if err != nil {
+		return err
+	}
+	if len(snapshot.Memes) == 0 {
+		return errors.New("snapshot contains no memes")
+	}
+
+	mc.mu.Lock()
+	mc.index = nil
+	mc.snapshot = snapshot
+	mc.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,277 @@
+package imgflipgo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func TestMemeCatalogCaches(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	catalog := imgflipgo.NewMemeCatalog(srv.NewClient(), time.Hour)
+	for i := 0; i < 3; i++ {
+		memes, err := catalog.Memes(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memes) != len(imgflipgotest.Memes) {
+			t.Fatalf("expected %d memes, got %d", len(imgflipgotest.Memes), len(memes))
+		}
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Fatalf("expected 1 request, got %d", n)
+	}
+
+	if _, ok := catalog.Lookup(imgflipgotest.Memes[0].ID); !ok {
+		t.Fatal("expected cached meme to be found")
+	}
+}
+
+func TestMemeCatalogIndex(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	catalog := imgflipgo.NewMemeCatalog(srv.NewClient(), time.Hour)
+	idx, err := catalog.Index(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := catalog.Index(context.Background())
+	if err != nil || again != idx {
+		t.Errorf("expected the index to be reused, got %v", err)
+	}
+
+	meme, err := catalog.Find(context.Background(), "distracted bf")
+	if err != nil || meme.Name != "Distracted Boyfriend" {
+		t.Errorf("expected Distracted Boyfriend, got %+v, %v", meme, err)
+	}
+	meme, err = catalog.Find(context.Background(), imgflipgotest.Memes[1].ID)
+	if err != nil || meme != imgflipgotest.Memes[1] {
+		t.Errorf("expected %s to be found by ID, got %+v, %v", imgflipgotest.Memes[1].ID, meme, err)
+	}
+	_, err = catalog.Find(context.Background(), "zzzzzz")
+	if !errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+
+	srv.SetMemes([]imgflipgo.Meme{{ID: "1", Name: "Brand New Template"}})
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	meme, err = catalog.Find(context.Background(), "brand new")
+	if err != nil || meme.ID != "1" {
+		t.Errorf("expected the index to be rebuilt after a refresh, got %+v, %v", meme, err)
+	}
+}
+
+func TestMemeCatalogConditionalRefresh(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	catalog := imgflipgo.NewMemeCatalog(srv.NewClient(), 0)
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	first := catalog.Snapshot()
+	if first.ETag == "" {
+		t.Fatal("expected an ETag to be cached")
+	}
+
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	requests := srv.Requests()
+	if got := requests[len(requests)-1].Header.Get("If-None-Match"); got != first.ETag {
+		t.Fatalf("expected If-None-Match %s, got %q", first.ETag, got)
+	}
+	if second := catalog.Snapshot(); len(second.Memes) != len(first.Memes) || !second.FetchedAt.After(first.FetchedAt) {
+		t.Fatal("expected a 304 to renew the cached memes")
+	}
+
+	srv.SetMemes(imgflipgotest.Memes[:2])
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if third := catalog.Snapshot(); len(third.Memes) != 2 || third.ETag == first.ETag {
+		t.Fatalf("expected changed memes to be fetched, got %d memes", len(third.Memes))
+	}
+}
+
+func TestMemeCatalogPersistence(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	path := filepath.Join(t.TempDir(), "memes.json")
+
+	catalog := imgflipgo.NewMemeCatalog(srv.NewClient(), time.Hour)
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := catalog.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	// With the server gone, a loaded snapshot is served even though it can't be
+	// refreshed.
+	coldStart := imgflipgo.NewMemeCatalog(srv.NewClient(), 0)
+	if err := coldStart.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	memes, err := coldStart.Memes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != len(imgflipgotest.Memes) {
+		t.Fatalf("expected %d memes, got %d", len(imgflipgotest.Memes), len(memes))
+	}
+}
+
+func TestMemeCatalogBackgroundRefresh(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	transport := &countingTransport{base: srv.Client().Transport}
+	catalog := imgflipgo.NewMemeCatalog(srv.NewClient(imgflipgo.WithHTTPClient(&http.Client{Transport: transport})), time.Hour)
+	catalog.Start(5 * time.Millisecond)
+	defer catalog.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for len(catalog.Snapshot().Memes) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("catalog was not refreshed in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	catalog.Stop()
+
+	// The server may still be handling a request that Stop abandoned, so count
+	// the requests the catalog made rather than the ones the server saw.
+	n := transport.count()
+	time.Sleep(20 * time.Millisecond)
+	if transport.count() != n {
+		t.Fatal("expected no refreshes after Stop")
+	}
+}
+
+// countingTransport counts the requests that have been sent through it.
+type countingTransport struct {
+	base  http.RoundTripper
+	delay time.Duration
+
+	mu sync.Mutex
+	n  int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.n++
+	t.mu.Unlock()
+	time.Sleep(t.delay)
+	return t.base.RoundTrip(req)
+}
+
+func (t *countingTransport) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n
+}
+
+func TestMemeCatalogConcurrentRefresh(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	// Slow the request down so that every caller queues behind the first.
+	transport := &countingTransport{base: srv.Client().Transport, delay: 20 * time.Millisecond}
+	catalog := imgflipgo.NewMemeCatalog(srv.NewClient(imgflipgo.WithHTTPClient(&http.Client{Transport: transport})), time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := catalog.Memes(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := transport.count(); n != 1 {
+		t.Errorf("expected concurrent callers to share 1 request, got %d", n)
+	}
+
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := transport.count(); n != 2 {
+		t.Errorf("expected an explicit Refresh to fetch again, got %d requests", n)
+	}
+}
+
+func TestMemeCatalogUnavailable(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	srv.FailNext(1, http.StatusServiceUnavailable)
+
+	_, err := imgflipgo.NewMemeCatalog(srv.NewClient(), time.Hour).Memes(context.Background())
+	if err == nil {
+		t.Fatal("expected an error when nothing is cached and the API is unavailable")
+	}
+}
+
+func TestMemeCatalogOutage(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	transport := &countingTransport{base: srv.Client().Transport}
+	client := srv.NewClient(imgflipgo.WithHTTPClient(&http.Client{Transport: transport}))
+	path := filepath.Join(t.TempDir(), "memes.json")
+	stale := imgflipgo.NewMemeCatalog(client, time.Hour)
+	if err := stale.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// A TTL shorter than the time since the snapshot was fetched makes it stale.
+	time.Sleep(100 * time.Millisecond)
+	catalog := imgflipgo.NewMemeCatalog(client, 50*time.Millisecond)
+	if err := catalog.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	srv.FailNext(1, http.StatusServiceUnavailable)
+	before := transport.count()
+	for i := 0; i < 3; i++ {
+		memes, err := catalog.Memes(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memes) != len(imgflipgotest.Memes) {
+			t.Fatalf("expected %d stale memes, got %d", len(imgflipgotest.Memes), len(memes))
+		}
+	}
+	if n := transport.count() - before; n != 1 {
+		t.Errorf("expected 1 request until the TTL passes after a failed refresh, got %d", n)
+	}
+
+	// Once the TTL passes, the catalog tries again.
+	time.Sleep(100 * time.Millisecond)
+	retried := time.Now()
+	if _, err := catalog.Memes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := transport.count() - before; n != 2 {
+		t.Errorf("expected the catalog to retry after the TTL, got %d requests", n)
+	}
+	if snapshot := catalog.Snapshot(); snapshot.FetchedAt.Before(retried) {
+		t.Errorf("expected the retry to renew the memes, fetched at %v", snapshot.FetchedAt)
+	}
+}
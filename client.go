@@ -0,0 +1,226 @@
+package imgflipgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the root that all Imgflip API endpoints live under.
+const DefaultBaseURL = "https://api.imgflip.com"
+
+const (
+	captionImagePath = "caption_image"
+	captionGifPath   = "caption_gif"
+	autoMemePath     = "automeme"
+	aiMemePath       = "ai_meme"
+	getMemesPath     = "get_memes"
+	getMemePath      = "get_meme"
+	searchMemesPath  = "search_memes"
+)
+
+// Client makes requests against the Imgflip API. Create one with NewClient.
+// A Client is safe for concurrent use by multiple goroutines.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	username   string
+	password   string
+	userAgent  string
+
+	maxImageSize int64
+
+	retryPolicy          RetryPolicy
+	rateLimiter          *RateLimiter
+	endpointRateLimiters map[string]*RateLimiter
+}
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the *http.Client used to make requests. Defaults to
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		c.httpClient = httpClient
+	}
+}
+
+// WithBaseURL points the Client at a different API root, e.g. a proxy or a
+// fake server in tests. Defaults to DefaultBaseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithCredentials sets the imgflip username and password used for any request
+// that does not specify its own.
+func WithCredentials(username, password string) ClientOption {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// NewClient creates a Client configured by the provided options.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
+		httpClient:   http.DefaultClient,
+		baseURL:      DefaultBaseURL,
+		maxImageSize: DefaultMaxImageSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// DefaultClient is the Client used by the package-level API functions.
+var DefaultClient = NewClient()
+
+// credentials returns the provided username and password, falling back to the
+// Client's defaults if neither was provided.
+func (c *Client) credentials(username, password string) (string, string) {
+	if username == "" && password == "" {
+		return c.username, c.password
+	}
+	return username, password
+}
+
+func (c *Client) endpointURL(endpoint string) string {
+	return c.baseURL + "/" + endpoint
+}
+
+// response is the parts of an HTTP response that API calls care about.
+type response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// do sends a request to the given endpoint and returns the response, waiting
+// for the Client's rate limiters and retrying according to its RetryPolicy. If
+// form is nil a GET is made, otherwise the encoded form is POSTed. Any header values are
+// added to the request. If ctx is done before the response has been read, the
+// returned error wraps ctx.Err().
+func (c *Client) do(ctx context.Context, endpoint string, form []byte, header http.Header) (*response, error) {
+	method := http.MethodGet
+	if form != nil {
+		method = http.MethodPost
+	}
+
+	policy := c.retryPolicy
+	maxAttempts := policy.attempts()
+	var attemptErrs []error
+	for attempt := 1; ; attempt++ {
+		err := c.waitRateLimit(ctx, endpoint)
+		if err != nil {
+			return nil, newRetryError(endpoint, attemptErrs, err)
+		}
+
+		resp, err := c.doOnce(ctx, endpoint, method, form, header)
+		var retryAfter time.Duration
+		if err == nil {
+			if !policy.retryableStatus(resp.StatusCode) || (attempt >= maxAttempts && len(attemptErrs) == 0) {
+				return resp, nil
+			}
+			err = statusError(endpoint, resp)
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+		attemptErrs = append(attemptErrs, err)
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			return nil, newRetryError(endpoint, attemptErrs, nil)
+		}
+
+		delay := policy.backoff(attempt, retryAfter)
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryEvent{Endpoint: endpoint, Attempt: attempt, Err: err, Delay: delay})
+		}
+		err = sleepContext(ctx, delay)
+		if err != nil {
+			return nil, newRetryError(endpoint, attemptErrs, contextError(ctx, endpoint, err))
+		}
+	}
+}
+
+// doOnce makes a single attempt at a request, adding any extra header values.
+func (c *Client) doOnce(ctx context.Context, endpoint, method string, body []byte, header http.Header) (*response, error) {
+	var req *http.Request
+	var err error
+	if method == http.MethodGet {
+		req, err = http.NewRequestWithContext(ctx, method, c.endpointURL(endpoint), nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, c.endpointURL(endpoint), bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, contextError(ctx, endpoint, err)
+	}
+	if resp == nil {
+		return nil, errors.New("nil response received")
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, contextError(ctx, endpoint, err)
+	}
+	return &response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
+
+// call sends a request to the given endpoint and decodes the JSON response
+// body into v. A response that is not JSON is reported as an *APIError if its
+// status indicates failure.
+func (c *Client) call(ctx context.Context, endpoint string, form []byte, v interface{}) (*response, error) {
+	resp, err := c.do(ctx, endpoint, form, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(resp.Body, v)
+	if err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return resp, newAPIError(endpoint, resp, "")
+		}
+		return resp, err
+	}
+	return resp, nil
+}
+
+// contextError returns an error wrapping ctx.Err() if ctx is done, otherwise
+// err is returned unchanged.
+func contextError(ctx context.Context, endpoint string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s request aborted: %w", endpoint, ctxErr)
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package imgflipgo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func TestClientCaptionImageDefaults(t *testing.T) {
+	const (
+		username  = "client_user"
+		password  = "client_pass"
+		userAgent = "imgflipgo-test"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/caption_image" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("User-Agent"); got != userAgent {
+			t.Errorf("expected User-Agent %q, got %q", userAgent, got)
+		}
+		if r.PostFormValue("username") != username || r.PostFormValue("password") != password {
+			t.Errorf("client credentials were not sent, got %s/%s", r.PostFormValue("username"), r.PostFormValue("password"))
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"url":"https://i.imgflip.com/test.jpg","page_url":"https://imgflip.com/i/test"}}`)
+	}))
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(
+		imgflipgo.WithBaseURL(srv.URL+"/"),
+		imgflipgo.WithHTTPClient(srv.Client()),
+		imgflipgo.WithCredentials(username, password),
+		imgflipgo.WithUserAgent(userAgent),
+	)
+
+	req := (&imgflipgo.CaptionRequest{TemplateID: testTemplateID}).SetTopText("Top Text")
+	resp, err := client.CaptionImage(req)
+	expectSuccess(t, resp, err)
+	if req.Username != "" || req.Password != "" {
+		t.Fatal("CaptionImage should not modify the caller's request")
+	}
+}
+
+func TestClientRequestCredentialsOverrideDefaults(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("username") != "request_user" || r.PostFormValue("password") != "request_pass" {
+			fmt.Fprint(w, `{"success":false,"error_message":"Invalid username/password"}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"url":"https://i.imgflip.com/test.jpg"}}`)
+	}))
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL), imgflipgo.WithCredentials("client_user", "client_pass"))
+	resp, err := client.CaptionImage((&imgflipgo.CaptionRequest{
+		TemplateID: testTemplateID,
+		Username:   "request_user",
+		Password:   "request_pass",
+	}).SetTopText("Top Text"))
+	expectSuccess(t, resp, err)
+}
+
+func TestClientGetMemes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/get_memes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"memes":[{"id":"181913649","name":"Drake Hotline Bling","box_count":2}]}}`)
+	}))
+	defer srv.Close()
+
+	memes, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL)).GetMemes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != 1 || memes[0].ID != "181913649" || memes[0].BoxCount != 2 {
+		t.Fatalf("unexpected memes %+v", memes)
+	}
+}
+
+func TestClientContextCanceled(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetMemesContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	resp, err := client.CaptionImageContext(ctx, (&imgflipgo.CaptionRequest{
+		TemplateID: testTemplateID,
+		Username:   "user",
+		Password:   "pass",
+	}).SetTopText("Top Text"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+	expectFailure(t, resp, err)
+}
@@ -0,0 +1,89 @@
+// Command imgflip-server serves the JSON API of package server, captioning and
+// searching templates with server-side imgflip credentials.
+//
+// Usage:
+//
+//	imgflip-server [--addr ADDR] [--catalog-ttl DURATION]
+//
+// Credentials are read from the IMGFLIP_API_USERNAME and IMGFLIP_API_PASSWORD
+// environment variables, which may also be set in a .env file in the working
+// directory. IMGFLIP_API_URL overrides the API base URL.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/server"
+	"github.com/joho/godotenv"
+)
+
+const (
+	ImgflipAPIUserEnv string = "IMGFLIP_API_USERNAME"
+	ImgflipAPIPassEnv string = "IMGFLIP_API_PASSWORD"
+	ImgflipAPIURLEnv  string = "IMGFLIP_API_URL"
+)
+
+// shutdownTimeout is how long in-flight requests are given to finish after an
+// interrupt.
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	addr := flag.String("addr", ":8080", "`address` to listen on")
+	catalogTTL := flag.Duration("catalog-ttl", server.DefaultCatalogTTL, "how long to cache templates before refreshing them")
+	flag.Parse()
+	if *catalogTTL <= 0 {
+		log.Fatal("imgflip-server: --catalog-ttl must be positive")
+	}
+
+	godotenv.Load()
+	username, password := os.Getenv(ImgflipAPIUserEnv), os.Getenv(ImgflipAPIPassEnv)
+	if username == "" || password == "" {
+		log.Fatalf("imgflip-server: %s and %s must be set", ImgflipAPIUserEnv, ImgflipAPIPassEnv)
+	}
+
+	opts := []imgflipgo.ClientOption{
+		imgflipgo.WithCredentials(username, password),
+		imgflipgo.WithUserAgent("imgflipgo-server"),
+		imgflipgo.WithHTTPClient(&http.Client{Timeout: 30 * time.Second}),
+	}
+	if baseURL := os.Getenv(ImgflipAPIURLEnv); baseURL != "" {
+		opts = append(opts, imgflipgo.WithBaseURL(baseURL))
+	}
+	client := imgflipgo.NewClient(opts...)
+	catalog := imgflipgo.NewMemeCatalog(client, *catalogTTL)
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server.New(client, server.WithCatalog(catalog)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	catalog.Start(*catalogTTL)
+	defer catalog.Stop()
+
+	log.Printf("imgflip-server: listening on %s", *addr)
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("imgflip-server: %v", err)
+	}
+	<-shutdown
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/memespec"
+)
+
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("list", "")
+	jsonOutput := fs.Bool("json", false, "print templates as JSON")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return a.usageError(fs, "unexpected arguments")
+	}
+
+	memes, err := a.client.GetMemesContext(ctx)
+	if err != nil {
+		return err
+	}
+	return a.printMemes(memes, *jsonOutput)
+}
+
+func (a *app) search(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("search", "QUERY")
+	jsonOutput := fs.Bool("json", false, "print templates as JSON")
+	nsfw := fs.Bool("nsfw", false, "include NSFW templates")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return a.usageError(fs, "missing query")
+	}
+	if err := a.requireCredentials(); err != nil {
+		return err
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	memes, err := a.client.SearchMemesContext(ctx, query, imgflipgo.SearchIncludeNSFW(*nsfw))
+	if err != nil {
+		return err
+	}
+	return a.printMemes(memes, *jsonOutput)
+}
+
+func (a *app) printMemes(memes []imgflipgo.Meme, jsonOutput bool) error {
+	if jsonOutput {
+		if memes == nil {
+			memes = []imgflipgo.Meme{}
+		}
+		return a.writeJSON(memes)
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tBOXES\tSIZE")
+	for _, meme := range memes {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%dx%d\n", meme.ID, meme.Name, meme.BoxCount, meme.Width, meme.Height)
+	}
+	return tw.Flush()
+}
+
+// captionOutput is what caption prints with --json.
+type captionOutput struct {
+	URL     string `json:"url"`
+	PageURL string `json:"page_url"`
+	File    string `json:"file,omitempty"`
+}
+
+func (a *app) caption(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("caption", "")
+	req := &imgflipgo.CaptionRequest{}
+	boxes := &textBoxFlags{}
+	fs.StringVar(&req.TemplateID, "template", "", "template ID to caption (required)")
+	top := fs.String("top", "", "top text")
+	bottom := fs.String("bottom", "", "bottom text")
+	fs.Var(boxes.text(), "box", "add a text box containing `text`; repeat for more boxes (replaces --top and --bottom)")
+	fs.Var(boxes.color(false), "color", "text `color` of the preceding --box, e.g. ffffff or orange")
+	fs.Var(boxes.color(true), "outline-color", "outline `color` of the preceding --box, e.g. 000000 or black")
+	font := fs.String("font", "", "font: impact or arial")
+	maxFontSize := fs.Uint("max-font-size", 0, "maximum font size in pixels")
+	output := fs.String("output", "", "also download the captioned image to this file")
+	jsonOutput := fs.Bool("json", false, "print the result as JSON")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return a.usageError(fs, "unexpected arguments")
+	}
+	if req.TemplateID == "" {
+		return a.usageError(fs, "--template is required")
+	}
+	if err := a.requireCredentials(); err != nil {
+		return err
+	}
+
+	if *top != "" {
+		req.SetTopText(*top)
+	}
+	if *bottom != "" {
+		req.SetBottomText(*bottom)
+	}
+	req.TextBoxes = boxes.boxes
+	switch imgflipgo.Font(*font) {
+	case "":
+	case imgflipgo.FontImpact, imgflipgo.FontArial:
+		req.SetFont(imgflipgo.Font(*font))
+	default:
+		return a.usageError(fs, fmt.Sprintf("unknown font %q", *font))
+	}
+	if *maxFontSize > 0 {
+		req.SetMaxFontSize(*maxFontSize)
+	}
+
+	resp, err := a.client.CaptionImageContext(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	out := captionOutput{URL: resp.Data.URL, PageURL: resp.Data.PageURL}
+	if *output != "" {
+		out.File, err = a.downloadTo(ctx, resp.Data.URL, *output)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *jsonOutput {
+		return a.writeJSON(out)
+	}
+	fmt.Fprintln(a.stdout, out.URL)
+	return nil
+}
+
+// downloadOutput is what download prints with --json.
+type downloadOutput struct {
+	URL   string `json:"url"`
+	File  string `json:"file"`
+	Bytes int64  `json:"bytes"`
+}
+
+func (a *app) download(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("download", "URL")
+	output := fs.String("output", "", `file to write the image to, or "-" for stdout (default: the URL's file name)`)
+	jsonOutput := fs.Bool("json", false, "print the result as JSON")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return a.usageError(fs, "expected exactly one URL")
+	}
+	imageURL := fs.Arg(0)
+
+	if *output == "-" {
+		if *jsonOutput {
+			return a.usageError(fs, `--json can't be used with --output -`)
+		}
+		body, err := a.client.OpenImage(ctx, imageURL)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		_, err = io.Copy(a.stdout, body)
+		return err
+	}
+
+	file, err := a.downloadTo(ctx, imageURL, *output)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOutput {
+		return a.writeJSON(downloadOutput{URL: imageURL, File: file, Bytes: info.Size()})
+	}
+	fmt.Fprintln(a.stdout, file)
+	return nil
+}
+
+// batchOutput is what batch prints for each meme with --json.
+type batchOutput struct {
+	Name    string `json:"name,omitempty"`
+	URL     string `json:"url,omitempty"`
+	PageURL string `json:"page_url,omitempty"`
+	File    string `json:"file,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (a *app) batch(ctx context.Context, args []string) error {
+	fs := a.newFlagSet("batch", "FILE")
+	concurrency := fs.Int("concurrency", imgflipgo.DefaultBatchConcurrency, "number of memes to caption at once")
+	jsonOutput := fs.Bool("json", false, "print the results as JSON")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return a.usageError(fs, "expected exactly one spec file")
+	}
+	if err := a.requireCredentials(); err != nil {
+		return err
+	}
+
+	spec, err := memespec.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	results, err := memespec.Run(ctx, a.client, spec, imgflipgo.BatchConcurrency(*concurrency))
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	outputs := make([]batchOutput, len(results))
+	for i, result := range results {
+		outputs[i] = batchOutput{
+			Name:    result.Entry.Name,
+			URL:     result.Response.Data.URL,
+			PageURL: result.Response.Data.PageURL,
+			File:    result.Output,
+		}
+		if result.Err != nil {
+			failed++
+			outputs[i].Error = result.Err.Error()
+		}
+	}
+
+	if *jsonOutput {
+		err = a.writeJSON(outputs)
+		if err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "MEME\tRESULT\tFILE")
+		for i, out := range outputs {
+			result := out.URL
+			if out.Error != "" {
+				result = "error: " + out.Error
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", results[i].Entry.Label(i), result, out.File)
+		}
+		tw.Flush()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d memes failed", failed, len(results))
+	}
+	return nil
+}
+
+// downloadTo downloads the image at imageURL to file, or to the URL's file name
+// in the working directory if file is empty. It returns the file written.
+func (a *app) downloadTo(ctx context.Context, imageURL, file string) (string, error) {
+	if file == "" {
+		u, err := url.Parse(imageURL)
+		if err != nil {
+			return "", err
+		}
+		file = path.Base(u.Path)
+		if file == "/" || file == "." {
+			return "", fmt.Errorf("can't determine a file name from %s, use --output", imageURL)
+		}
+	}
+
+	body, err := a.client.OpenImage(ctx, imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return "", err
+	}
+	return file, nil
+}
+
+// textBoxFlags collects --box flags, and the per-box flags that modify the most
+// recent one.
+type textBoxFlags struct {
+	boxes []imgflipgo.TextBox
+}
+
+// boxFlag is a flag.Value that applies each value it's given with set.
+type boxFlag struct {
+	set func(string) error
+}
+
+func (f boxFlag) String() string     { return "" }
+func (f boxFlag) Set(v string) error { return f.set(v) }
+
+func (b *textBoxFlags) text() boxFlag {
+	return boxFlag{set: func(v string) error {
+		b.boxes = append(b.boxes, imgflipgo.TextBox{Text: v})
+		return nil
+	}}
+}
+
+func (b *textBoxFlags) color(outline bool) boxFlag {
+	return boxFlag{set: func(v string) error {
+		if len(b.boxes) == 0 {
+			return errors.New("must follow a --box")
+		}
+		c, err := imgflipgo.ParseColor(v)
+		if err != nil {
+			return err
+		}
+		tb := &b.boxes[len(b.boxes)-1]
+		if outline {
+			tb.SetOutlineColor(c)
+		} else {
+			tb.SetColor(c)
+		}
+		return nil
+	}}
+}
@@ -0,0 +1,172 @@
+// Command imgflip captions images and browses templates from the command line.
+//
+// Usage:
+//
+//	imgflip list [--json]
+//	imgflip search [--json] [--nsfw] QUERY
+//	imgflip caption --template ID [--top TEXT] [--bottom TEXT] [--box TEXT [--color C] [--outline-color C]]... [--font impact|arial] [--max-font-size PX] [--output PATH] [--json]
+//	imgflip download [--output PATH] [--json] URL
+//	imgflip batch [--concurrency N] [--json] FILE
+//
+// Credentials are read from the IMGFLIP_API_USERNAME and IMGFLIP_API_PASSWORD
+// environment variables, which may also be set in a .env file in the working
+// directory. IMGFLIP_API_URL overrides the API base URL.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/joho/godotenv"
+)
+
+const (
+	ImgflipAPIUserEnv string = "IMGFLIP_API_USERNAME"
+	ImgflipAPIPassEnv string = "IMGFLIP_API_PASSWORD"
+	ImgflipAPIURLEnv  string = "IMGFLIP_API_URL"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const usage = `usage: imgflip <command> [flags] [args]
+
+Commands:
+  list      list popular templates
+  search    search templates by name (requires credentials)
+  caption   caption a template (requires credentials)
+  download  download an image, e.g. a captioned meme
+  batch     caption every meme in a spec file (requires credentials)
+
+Run "imgflip <command> -h" for a command's flags.
+
+Credentials are read from the ` + ImgflipAPIUserEnv + ` and ` + ImgflipAPIPassEnv + `
+environment variables, or a .env file in the working directory.
+`
+
+// errUsage is returned by commands when they are invoked incorrectly. The
+// details have already been printed.
+var errUsage = errors.New("usage error")
+
+func main() {
+	godotenv.Load()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Getenv, os.Stdout, os.Stderr))
+}
+
+// app holds what every command needs.
+type app struct {
+	client   *imgflipgo.Client
+	username string
+	password string
+	stdout   io.Writer
+	stderr   io.Writer
+}
+
+// run executes the command in args, reading configuration with getenv, and
+// returns the process exit code.
+func run(ctx context.Context, args []string, getenv func(string) string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	a := &app{
+		username: getenv(ImgflipAPIUserEnv),
+		password: getenv(ImgflipAPIPassEnv),
+		stdout:   stdout,
+		stderr:   stderr,
+	}
+	opts := []imgflipgo.ClientOption{
+		imgflipgo.WithCredentials(a.username, a.password),
+		imgflipgo.WithUserAgent("imgflipgo-cli"),
+	}
+	if baseURL := getenv(ImgflipAPIURLEnv); baseURL != "" {
+		opts = append(opts, imgflipgo.WithBaseURL(baseURL))
+	}
+	a.client = imgflipgo.NewClient(opts...)
+
+	commands := map[string]func(context.Context, []string) error{
+		"list":     a.list,
+		"search":   a.search,
+		"caption":  a.caption,
+		"download": a.download,
+		"batch":    a.batch,
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			fmt.Fprint(stdout, usage)
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "imgflip: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+
+	err := cmd(ctx, args[1:])
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "imgflip: %v\n", err)
+	return exitError
+}
+
+// newFlagSet creates a FlagSet for a command that reports errors to stderr.
+func (a *app) newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(a.stderr, strings.TrimSpace("usage: imgflip "+name+" [flags] "+args))
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args with fs, converting failures into errUsage.
+func parse(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return errUsage
+	}
+	return err
+}
+
+// usageError prints msg and fs's usage, and returns errUsage.
+func (a *app) usageError(fs *flag.FlagSet, msg string) error {
+	fmt.Fprintf(a.stderr, "imgflip %s: %s\n", fs.Name(), msg)
+	fs.Usage()
+	return errUsage
+}
+
+func (a *app) requireCredentials() error {
+	if a.username == "" || a.password == "" {
+		return fmt.Errorf("%s and %s must be set", ImgflipAPIUserEnv, ImgflipAPIPassEnv)
+	}
+	return nil
+}
+
+func (a *app) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
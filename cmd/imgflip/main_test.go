@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+// runCLI runs the command in args against srv, returning its exit code and
+// output.
+func runCLI(srv *imgflipgotest.Server, env map[string]string, args ...string) (int, string, string) {
+	username, password := srv.Credentials()
+	vars := map[string]string{
+		ImgflipAPIUserEnv: username,
+		ImgflipAPIPassEnv: password,
+		ImgflipAPIURLEnv:  srv.URL,
+	}
+	for k, v := range env {
+		vars[k] = v
+	}
+
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := run(context.Background(), args, func(k string) string { return vars[k] }, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestList(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	code, stdout, stderr := runCLI(srv, nil, "list", "--json")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	memes := []imgflipgo.Meme{}
+	if err := json.Unmarshal([]byte(stdout), &memes); err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != len(imgflipgotest.Memes) {
+		t.Errorf("expected %d memes, got %d", len(imgflipgotest.Memes), len(memes))
+	}
+
+	code, stdout, _ = runCLI(srv, nil, "list")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d", exitOK, code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != len(imgflipgotest.Memes)+1 {
+		t.Fatalf("expected a header and %d rows, got %d lines", len(imgflipgotest.Memes), len(lines))
+	}
+	if !strings.Contains(lines[1], imgflipgotest.Memes[0].Name) {
+		t.Errorf("expected the first row to describe %q, got %q", imgflipgotest.Memes[0].Name, lines[1])
+	}
+}
+
+func TestSearch(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	code, stdout, stderr := runCLI(srv, nil, "search", "--json", "drake")
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	memes := []imgflipgo.Meme{}
+	if err := json.Unmarshal([]byte(stdout), &memes); err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != 1 || memes[0].Name != "Drake Hotline Bling" {
+		t.Errorf("expected Drake Hotline Bling, got %+v", memes)
+	}
+
+	code, _, stderr = runCLI(srv, map[string]string{ImgflipAPIUserEnv: ""}, "search", "drake")
+	if code != exitError || !strings.Contains(stderr, ImgflipAPIUserEnv) {
+		t.Errorf("expected a missing credentials error, got %d: %s", code, stderr)
+	}
+}
+
+func TestCaption(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	output := filepath.Join(t.TempDir(), "meme.jpg")
+
+	code, stdout, stderr := runCLI(srv, nil, "caption",
+		"--template", imgflipgotest.Memes[0].ID,
+		"--box", "first", "--color", "red",
+		"--box", "second", "--outline-color", "#00ff00",
+		"--font", "arial",
+		"--output", output,
+		"--json",
+	)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+
+	out := captionOutput{}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.URL == "" || out.File != output {
+		t.Errorf("unexpected output %+v", out)
+	}
+	if info, err := os.Stat(output); err != nil || info.Size() == 0 {
+		t.Errorf("expected the image to be downloaded, got %v", err)
+	}
+
+	requests := srv.Requests()
+	req := requests[len(requests)-1]
+	if req.Form.Get("font") != "arial" {
+		t.Errorf("expected font arial, got %q", req.Form.Get("font"))
+	}
+	if len(req.TextBoxes) != 2 {
+		t.Fatalf("expected 2 text boxes, got %d", len(req.TextBoxes))
+	}
+	if req.TextBoxes[0].Text != "first" || req.TextBoxes[0].Color == nil || *req.TextBoxes[0].Color != 0xff0000 {
+		t.Errorf("unexpected first box %+v", req.TextBoxes[0])
+	}
+	if req.TextBoxes[1].Text != "second" || req.TextBoxes[1].OutlineColor == nil || *req.TextBoxes[1].OutlineColor != 0x00ff00 {
+		t.Errorf("unexpected second box %+v", req.TextBoxes[1])
+	}
+}
+
+func TestCaptionErrors(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"missing template", []string{"caption", "--top", "text"}, exitUsage},
+		{"color without box", []string{"caption", "--template", "1", "--color", "ffffff"}, exitUsage},
+		{"invalid color", []string{"caption", "--template", "1", "--box", "a", "--color", "whiteish"}, exitUsage},
+		{"unknown font", []string{"caption", "--template", "1", "--top", "a", "--font", "comic"}, exitUsage},
+		{"unknown template", []string{"caption", "--template", "1", "--top", "a"}, exitError},
+		{"unknown command", []string{"frobnicate"}, exitUsage},
+	}
+	for _, test := range tests {
+		code, _, stderr := runCLI(srv, nil, test.args...)
+		if code != test.code {
+			t.Errorf("%s: expected exit code %d, got %d: %s", test.name, test.code, code, stderr)
+		}
+		if stderr == "" {
+			t.Errorf("%s: expected an error message", test.name)
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	resp, err := srv.NewClient().CaptionImage((&imgflipgo.CaptionRequest{TemplateID: imgflipgotest.Memes[0].ID}).SetTopText("top"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI(srv, nil, "download", "--output", "-", resp.Data.URL)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if len(stdout) == 0 {
+		t.Error("expected the image to be written to stdout")
+	}
+
+	output := filepath.Join(t.TempDir(), "image.jpg")
+	code, stdout, stderr = runCLI(srv, nil, "download", "--output", output, "--json", resp.Data.URL)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	out := downloadOutput{}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.File != output || out.Bytes == 0 {
+		t.Errorf("unexpected output %+v", out)
+	}
+
+	code, _, _ = runCLI(srv, nil, "download", srv.URL+"/get_memes")
+	if code != exitError {
+		t.Errorf("expected exit code %d for a non-image, got %d", exitError, code)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "memes.yaml")
+	spec := "memes:\n  - {name: ok, template_id: '181913649', top_text: a}\n  - {name: missing, template_id: '1', top_text: b}\n"
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI(srv, nil, "batch", "--json", path)
+	if code != exitError || !strings.Contains(stderr, "1 of 2 memes failed") {
+		t.Errorf("expected one meme to fail, got %d: %s", code, stderr)
+	}
+	outputs := []batchOutput{}
+	if err := json.Unmarshal([]byte(stdout), &outputs); err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(outputs))
+	}
+	if outputs[0].Name != "ok" || outputs[0].URL == "" || outputs[0].Error != "" {
+		t.Errorf("unexpected first result %+v", outputs[0])
+	}
+	if outputs[1].Name != "missing" || outputs[1].Error == "" {
+		t.Errorf("unexpected second result %+v", outputs[1])
+	}
+}
@@ -0,0 +1,134 @@
+package imgflipgo_test
+
+import (
+	"encoding/json"
+	"errors"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  imgflipgo.Color
+	}{
+		{"#ffa500", 0xFFA500},
+		{"#FFA500", 0xFFA500},
+		{"ffa500", 0xFFA500},
+		{"#fa0", 0xFFAA00},
+		{"orange", 0xFFA500},
+		{" Orange ", 0xFFA500},
+		{"rebeccapurple", 0x663399},
+		{"rgb(255,165,0)", 0xFFA500},
+		{"rgb( 255 , 165 , 0 )", 0xFFA500},
+		{"#000000", 0},
+		{"white", 0xFFFFFF},
+	}
+	for _, test := range tests {
+		got, err := imgflipgo.ParseColor(test.input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: expected %s, got %s", test.input, test.want, got)
+		}
+	}
+
+	for _, input := range []string{"", "#", "fa0", "#ffa5", "#ffa5000", "#gggggg", "orangey", "rgb(256,0,0)", "rgb(1,2)", "rgb(-1,0,0)"} {
+		_, err := imgflipgo.ParseColor(input)
+		if !errors.Is(err, imgflipgo.ErrInvalidColor) {
+			t.Errorf("%q: expected ErrInvalidColor, got %v", input, err)
+		}
+	}
+}
+
+func TestColorConversion(t *testing.T) {
+	c := imgflipgo.Color(0xFFA500)
+	if got := color.RGBAModel.Convert(c).(color.RGBA); got != (color.RGBA{R: 0xFF, G: 0xA5, B: 0x00, A: 0xFF}) {
+		t.Errorf("expected orange, got %v", got)
+	}
+	if got := imgflipgo.ColorOf(color.RGBA{R: 0xFF, G: 0xA5, B: 0x00, A: 0xFF}); got != c {
+		t.Errorf("expected %s, got %s", c, got)
+	}
+	if got := imgflipgo.ColorOf(c); got != c {
+		t.Errorf("expected %s to round trip, got %s", c, got)
+	}
+	if got := imgflipgo.RGB(0xFF, 0xA5, 0x00); got != c {
+		t.Errorf("expected %s, got %s", c, got)
+	}
+	if c.String() != "#ffa500" {
+		t.Errorf("expected #ffa500, got %s", c.String())
+	}
+}
+
+func TestColorJSON(t *testing.T) {
+	tb := (&imgflipgo.TextBox{Text: "text"}).SetColor(0xFFA500)
+	data, err := json.Marshal(tb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"color":"#ffa500"`) {
+		t.Errorf("expected color to be marshalled as a hex string, got %s", data)
+	}
+
+	for _, input := range []string{`{"color":"#ffa500"}`, `{"color":"orange"}`, `{"color":16753920}`} {
+		decoded := imgflipgo.TextBox{}
+		err := json.Unmarshal([]byte(input), &decoded)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", input, err)
+			continue
+		}
+		if decoded.Color == nil || *decoded.Color != 0xFFA500 {
+			t.Errorf("%s: expected orange, got %v", input, decoded.Color)
+		}
+	}
+
+	for _, input := range []string{`{"color":"orangey"}`, `{"color":16777216}`, `{"color":true}`} {
+		err := json.Unmarshal([]byte(input), &imgflipgo.TextBox{})
+		if !errors.Is(err, imgflipgo.ErrInvalidColor) {
+			t.Errorf("%s: expected ErrInvalidColor, got %v", input, err)
+		}
+	}
+
+	_, err = json.Marshal(imgflipgo.Color(0x1000000))
+	if !errors.Is(err, imgflipgo.ErrInvalidColor) {
+		t.Errorf("expected an out of range color to fail to marshal, got %v", err)
+	}
+}
+
+func TestColorOutOfRange(t *testing.T) {
+	req := &imgflipgo.CaptionRequest{
+		TemplateID: "1",
+		Username:   "user",
+		Password:   "pass",
+		TextBoxes: []imgflipgo.TextBox{
+			*(&imgflipgo.TextBox{Text: "text"}).SetColor(0x1000000).SetOutlineColor(imgflipgo.MaxColor),
+		},
+	}
+	err := req.Validate()
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Color 0x1000000 is greater than 0xffffff") {
+		t.Errorf("expected the out of range color to be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "OutlineColor") {
+		t.Errorf("expected MaxColor to be valid, got %v", err)
+	}
+
+	req.TextBoxes[0].SetColor(0xFFA500)
+	form, err := req.CreateHTTPFormBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := form.Get("boxes[0][color]"); got != "#ffa500" {
+		t.Errorf("expected #ffa500, got %q", got)
+	}
+	if got := form.Get("boxes[0][outline_color]"); got != "#ffffff" {
+		t.Errorf("expected #ffffff, got %q", got)
+	}
+}
@@ -0,0 +1,465 @@
+// Package discord implements a Discord slash command that captions templates,
+// using Discord's interaction webhook protocol, e.g.
+//
+//	/meme template:drake text:no tests | tests in prod
+//
+// The template option picks a template by ID or name, as with
+// MemeCatalog.Find, and autocompletes template names from the catalog. Each
+// "|" separated part of the text option becomes a TextBox. The captioned image
+// is posted to the channel in an embed.
+//
+// Handler is an http.Handler to use as the application's interactions endpoint
+// URL. It verifies each request's Ed25519 signature with the application's
+// public key before doing anything else. Register the command it serves with
+// the definition returned by Command.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/internal/command"
+)
+
+// DefaultAPIURL is the Discord API that deferred responses are sent to.
+const DefaultAPIURL = "https://discord.com/api/v10"
+
+// DefaultDeferAfter is how long a Handler waits for a caption before deferring
+// its response and sending the caption as a followup instead. Discord requires
+// a response within three seconds.
+const DefaultDeferAfter = command.DefaultDeferAfter
+
+// DefaultTimeout bounds how long captioning a command may take, including
+// deferred commands.
+const DefaultTimeout = command.DefaultTimeout
+
+// MaxChoices is the most autocomplete choices Discord accepts.
+const MaxChoices = 25
+
+// Names of the options of the command a Handler serves.
+const (
+	TemplateOption = "template"
+	TextOption     = "text"
+)
+
+// Errors reported when a request's signature can't be verified.
+var (
+	ErrMissingSignature = errors.New("missing Discord signature")
+	ErrInvalidSignature = errors.New("invalid Discord signature")
+)
+
+// InteractionType is the type of an Interaction.
+type InteractionType int
+
+const (
+	InteractionPing                InteractionType = 1
+	InteractionApplicationCommand  InteractionType = 2
+	InteractionCommandAutocomplete InteractionType = 4
+)
+
+// ResponseType is the type of a Response.
+type ResponseType int
+
+const (
+	ResponsePong                             ResponseType = 1
+	ResponseChannelMessageWithSource         ResponseType = 4
+	ResponseDeferredChannelMessageWithSource ResponseType = 5
+	ResponseAutocompleteResult               ResponseType = 8
+)
+
+// FlagEphemeral makes a message visible only to the user who invoked the
+// command.
+const FlagEphemeral = 1 << 6
+
+// Interaction is the part of an interaction, see
+// https://discord.com/developers/docs/interactions/receiving-and-responding,
+// that a Handler uses.
+type Interaction struct {
+	ID            string          `json:"id"`
+	ApplicationID string          `json:"application_id"`
+	Type          InteractionType `json:"type"`
+	Token         string          `json:"token"`
+	Data          *CommandData    `json:"data,omitempty"`
+}
+
+// CommandData is the data of an application command or autocomplete
+// interaction.
+type CommandData struct {
+	Name    string          `json:"name"`
+	Options []CommandOption `json:"options,omitempty"`
+}
+
+// CommandOption is an option value sent with a command.
+type CommandOption struct {
+	Name  string      `json:"name"`
+	Type  int         `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+
+	// Focused is set on the option being autocompleted.
+	Focused bool `json:"focused,omitempty"`
+}
+
+// Response is an interaction response. Data is a *MessageData or
+// *AutocompleteData, depending on Type.
+type Response struct {
+	Type ResponseType `json:"type"`
+	Data interface{}  `json:"data,omitempty"`
+}
+
+// MessageData is the content of a message response or followup.
+type MessageData struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+	Flags   int     `json:"flags,omitempty"`
+}
+
+// Embed is a Discord message embed.
+type Embed struct {
+	Title string      `json:"title,omitempty"`
+	URL   string      `json:"url,omitempty"`
+	Image *EmbedImage `json:"image,omitempty"`
+}
+
+// EmbedImage is the image of an Embed.
+type EmbedImage struct {
+	URL string `json:"url"`
+}
+
+// AutocompleteData is the content of an autocomplete response.
+type AutocompleteData struct {
+	Choices []Choice `json:"choices"`
+}
+
+// Choice is an autocomplete suggestion.
+type Choice struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ApplicationCommand is a slash command definition, as registered with
+// Discord's API.
+type ApplicationCommand struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Options     []ApplicationCommandOption `json:"options,omitempty"`
+}
+
+// ApplicationCommandOption is an option of an ApplicationCommand.
+type ApplicationCommandOption struct {
+	Type         int    `json:"type"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Required     bool   `json:"required,omitempty"`
+	Autocomplete bool   `json:"autocomplete,omitempty"`
+}
+
+// optionTypeString is the ApplicationCommandOption type of string options.
+const optionTypeString = 3
+
+// Command returns the definition of the command a Handler serves, under the
+// given name, for registering it with Discord, e.g. by PUTting it to
+// /applications/{application.id}/commands.
+func Command(name string) ApplicationCommand {
+	return ApplicationCommand{
+		Name:        name,
+		Description: "Caption a meme template",
+		Options: []ApplicationCommandOption{
+			{Type: optionTypeString, Name: TemplateOption, Description: "Template name or ID", Required: true, Autocomplete: true},
+			{Type: optionTypeString, Name: TextOption, Description: "Text for each box, separated by |", Required: true},
+		},
+	}
+}
+
+// Handler serves a Discord slash command. It is safe for concurrent use.
+type Handler struct {
+	publicKey  ed25519.PublicKey
+	captioner  command.Captioner
+	httpClient *http.Client
+	apiURL     string
+	errorLog   *log.Logger
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithCatalog sets the catalog that templates are looked up and autocompleted
+// from. By default, a catalog caching templates for an hour is created for the
+// Handler's Client.
+func WithCatalog(catalog *imgflipgo.MemeCatalog) Option {
+	return func(h *Handler) {
+		h.captioner.Catalog = catalog
+	}
+}
+
+// WithHTTPClient sets the client used to send deferred responses to Discord.
+// http.DefaultClient is used by default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(h *Handler) {
+		h.httpClient = httpClient
+	}
+}
+
+// WithAPIURL sets the Discord API that deferred responses are sent to, see
+// DefaultAPIURL.
+func WithAPIURL(apiURL string) Option {
+	return func(h *Handler) {
+		h.apiURL = strings.TrimRight(apiURL, "/")
+	}
+}
+
+// WithDeferAfter sets how long the Handler waits for a caption before
+// deferring the response, see DefaultDeferAfter. If d is not positive, every
+// response is deferred.
+func WithDeferAfter(d time.Duration) Option {
+	return func(h *Handler) {
+		h.captioner.DeferAfter = d
+	}
+}
+
+// WithTimeout sets how long captioning a command may take, see DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.captioner.Timeout = d
+	}
+}
+
+// WithErrorLog sets the logger that failures to send deferred responses are
+// reported to. By default, the log package's standard logger is used.
+func WithErrorLog(l *log.Logger) Option {
+	return func(h *Handler) {
+		h.errorLog = l
+	}
+}
+
+// NewHandler creates a Handler that verifies requests with the application's
+// public key, e.g. as decoded with hex.DecodeString from the developer portal,
+// and captions templates with client, or imgflipgo.DefaultClient if client is
+// nil. The client must be configured with imgflipgo.WithCredentials.
+func NewHandler(publicKey ed25519.PublicKey, client *imgflipgo.Client, opts ...Option) *Handler {
+	if client == nil {
+		client = imgflipgo.DefaultClient
+	}
+	h := &Handler{
+		publicKey:  publicKey,
+		httpClient: http.DefaultClient,
+		apiURL:     DefaultAPIURL,
+		errorLog:   log.Default(),
+	}
+	h.captioner.Client = client
+	h.captioner.DeferAfter = DefaultDeferAfter
+	h.captioner.Timeout = DefaultTimeout
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.captioner.Catalog == nil {
+		h.captioner.Catalog = imgflipgo.NewMemeCatalog(client, time.Hour)
+	}
+	return h
+}
+
+// Wait blocks until every deferred response has been sent.
+func (h *Handler) Wait() {
+	h.captioner.Wait()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, command.MaxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = Verify(h.publicKey, r.Header, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	interaction := Interaction{}
+	err = json.Unmarshal(body, &interaction)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch interaction.Type {
+	case InteractionPing:
+		writeResponse(w, Response{Type: ResponsePong})
+	case InteractionCommandAutocomplete:
+		h.autocomplete(w, r.Context(), interaction)
+	case InteractionApplicationCommand:
+		h.command(w, interaction)
+	default:
+		http.Error(w, fmt.Sprintf("unsupported interaction type %d", interaction.Type), http.StatusBadRequest)
+	}
+}
+
+// autocomplete suggests templates matching the focused option, or the most
+// popular templates if it's empty.
+func (h *Handler) autocomplete(w http.ResponseWriter, ctx context.Context, interaction Interaction) {
+	choices := []Choice{}
+	query := ""
+	if interaction.Data != nil {
+		for _, opt := range interaction.Data.Options {
+			if opt.Focused {
+				query, _ = opt.Value.(string)
+			}
+		}
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		memes, err := h.captioner.Catalog.Memes(ctx)
+		if err == nil {
+			for i := 0; i < len(memes) && i < MaxChoices; i++ {
+				choices = append(choices, choice(memes[i]))
+			}
+		}
+	} else {
+		idx, err := h.captioner.Catalog.Index(ctx)
+		if err == nil {
+			for _, match := range idx.Search(query, MaxChoices) {
+				choices = append(choices, choice(match.Meme))
+			}
+		}
+	}
+	writeResponse(w, Response{Type: ResponseAutocompleteResult, Data: &AutocompleteData{Choices: choices}})
+}
+
+// choice suggests meme, which is sent back as its ID. Discord limits names to
+// 100 characters.
+func choice(meme imgflipgo.Meme) Choice {
+	name := meme.Name
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:99]) + "…"
+	}
+	return Choice{Name: name, Value: meme.ID}
+}
+
+func (h *Handler) command(w http.ResponseWriter, interaction Interaction) {
+	query, texts, err := parseOptions(interaction.Data)
+	if err != nil {
+		writeResponse(w, ephemeral(fmt.Sprintf("Sorry, %v.", err)))
+		return
+	}
+
+	// If the caption takes too long, acknowledge the command now, and edit in
+	// the caption when it's ready.
+	result, ok := h.captioner.Start(query, texts, func(result command.Result) {
+		err := h.editOriginal(interaction, message(result))
+		if err != nil {
+			h.errorLog.Printf("discord: sending deferred response: %v", err)
+		}
+	})
+	if ok {
+		writeResponse(w, Response{Type: ResponseChannelMessageWithSource, Data: message(result)})
+		return
+	}
+	writeResponse(w, Response{Type: ResponseDeferredChannelMessageWithSource})
+}
+
+// message returns the message to respond to a command with.
+func message(result command.Result) *MessageData {
+	if result.Err != nil {
+		return &MessageData{Content: result.Err.Error(), Flags: FlagEphemeral}
+	}
+	return &MessageData{Embeds: []Embed{{
+		Title: result.Meme.Name,
+		URL:   result.Response.Data.PageURL,
+		Image: &EmbedImage{URL: result.Response.Data.URL},
+	}}}
+}
+
+// editOriginal replaces the deferred response to interaction with msg. Flags
+// can't be changed by an edit, so errors are visible to the whole channel.
+func (h *Handler) editOriginal(interaction Interaction, msg *MessageData) error {
+	if interaction.ApplicationID == "" || interaction.Token == "" {
+		return errors.New("interaction has no application ID or token")
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/webhooks/%s/%s/messages/@original", h.apiURL, interaction.ApplicationID, interaction.Token)
+	req, err := http.NewRequest(http.MethodPatch, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("editing the original response returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
+
+// parseOptions returns the template and text options of a command, splitting
+// the text into the text of each box with command.Parse.
+func parseOptions(data *CommandData) (string, []string, error) {
+	var query, text string
+	if data != nil {
+		for _, opt := range data.Options {
+			value, _ := opt.Value.(string)
+			switch opt.Name {
+			case TemplateOption:
+				query = value
+			case TextOption:
+				text = value
+			}
+		}
+	}
+	return command.Parse(query, text)
+}
+
+func ephemeral(content string) Response {
+	return Response{
+		Type: ResponseChannelMessageWithSource,
+		Data: &MessageData{Content: content, Flags: FlagEphemeral},
+	}
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Verify checks the X-Signature-Ed25519 and X-Signature-Timestamp headers of a
+// request with the given body, as described at
+// https://discord.com/developers/docs/interactions/overview#setting-up-an-endpoint-validating-security-request-headers.
+func Verify(publicKey ed25519.PublicKey, header http.Header, body []byte) error {
+	signature := header.Get("X-Signature-Ed25519")
+	timestamp := header.Get("X-Signature-Timestamp")
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize || len(publicKey) != ed25519.PublicKeySize {
+		return ErrInvalidSignature
+	}
+	msg := make([]byte, 0, len(timestamp)+len(body))
+	msg = append(msg, timestamp...)
+	msg = append(msg, body...)
+	if !ed25519.Verify(publicKey, msg, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
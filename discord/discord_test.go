@@ -0,0 +1,269 @@
+package discord_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2/discord"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+var publicKey, privateKey, _ = ed25519.GenerateKey(rand.Reader)
+
+// response is the union of the response data types, for decoding any
+// response.
+type response struct {
+	Type discord.ResponseType `json:"type"`
+	Data *struct {
+		discord.MessageData
+		Choices []discord.Choice `json:"choices"`
+	} `json:"data"`
+}
+
+// interaction builds a signed interaction request, as Discord would send it.
+func interaction(t *testing.T, in discord.Interaction) *http.Request {
+	t.Helper()
+	body, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/discord/interactions", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(privateKey, append([]byte(timestamp), body...))))
+	return req
+}
+
+// command builds a signed application command interaction.
+func command(t *testing.T, template, text string) *http.Request {
+	return interaction(t, discord.Interaction{
+		ID:            "1",
+		ApplicationID: "app",
+		Type:          discord.InteractionApplicationCommand,
+		Token:         "token",
+		Data: &discord.CommandData{
+			Name: "meme",
+			Options: []discord.CommandOption{
+				{Name: discord.TemplateOption, Type: 3, Value: template},
+				{Name: discord.TextOption, Type: 3, Value: text},
+			},
+		},
+	})
+}
+
+// serve sends req to h and decodes the response.
+func serve(t *testing.T, h http.Handler, req *http.Request) (int, response) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	resp := response{}
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%v: %s", err, rec.Body)
+		}
+	}
+	return rec.Code, resp
+}
+
+func TestPing(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := discord.NewHandler(publicKey, api.NewClient())
+
+	status, resp := serve(t, h, interaction(t, discord.Interaction{ID: "1", Type: discord.InteractionPing}))
+	if status != http.StatusOK || resp.Type != discord.ResponsePong {
+		t.Errorf("expected a PONG, got %d: %+v", status, resp)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := discord.NewHandler(publicKey, api.NewClient())
+
+	status, resp := serve(t, h, command(t, "drake", "no tests | tests in prod"))
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if resp.Type != discord.ResponseChannelMessageWithSource || resp.Data == nil || len(resp.Data.Embeds) != 1 {
+		t.Fatalf("expected a message with an embed, got %+v", resp)
+	}
+	embed := resp.Data.Embeds[0]
+	if embed.Title != "Drake Hotline Bling" || embed.Image == nil || embed.Image.URL == "" || embed.URL == "" {
+		t.Errorf("unexpected embed %+v", embed)
+	}
+	if resp.Data.Flags&discord.FlagEphemeral != 0 {
+		t.Error("expected the caption to be visible to the channel")
+	}
+
+	requests := api.Requests()
+	req := requests[len(requests)-1]
+	if req.Form.Get("template_id") != "181913649" {
+		t.Errorf("expected drake to be captioned, got %q", req.Form.Get("template_id"))
+	}
+	if len(req.TextBoxes) != 2 || req.TextBoxes[0].Text != "no tests" || req.TextBoxes[1].Text != "tests in prod" {
+		t.Errorf("unexpected text boxes %+v", req.TextBoxes)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := discord.NewHandler(publicKey, api.NewClient())
+
+	tests := []struct {
+		template string
+		text     string
+		want     string
+	}{
+		{"", "a", "no template specified"},
+		{"drake", "", "no text specified"},
+		{"drake", " | ", "no text specified"},
+		{"drake", strings.Repeat("a|", 20), "at most 20 text boxes"},
+		{"zzzzzz", "text", `No template matches "zzzzzz"`},
+	}
+	for _, test := range tests {
+		status, resp := serve(t, h, command(t, test.template, test.text))
+		if status != http.StatusOK {
+			t.Errorf("%q, %q: expected 200, got %d", test.template, test.text, status)
+			continue
+		}
+		if resp.Data == nil || resp.Data.Flags&discord.FlagEphemeral == 0 || !strings.Contains(resp.Data.Content, test.want) {
+			t.Errorf("%q, %q: expected an ephemeral %q, got %+v", test.template, test.text, test.want, resp.Data)
+		}
+	}
+}
+
+func TestDeferredCommand(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+
+	edited := make(chan discord.MessageData, 1)
+	webhooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/webhooks/app/token/messages/@original" {
+			t.Errorf("unexpected followup %s %s", r.Method, r.URL.Path)
+		}
+		msg := discord.MessageData{}
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Errorf("%v: %s", err, data)
+		}
+		edited <- msg
+	}))
+	defer webhooks.Close()
+
+	h := discord.NewHandler(publicKey, api.NewClient(), discord.WithDeferAfter(0), discord.WithAPIURL(webhooks.URL+"/"))
+	status, resp := serve(t, h, command(t, "distracted bf", "new framework | me | old framework"))
+	if status != http.StatusOK || resp.Type != discord.ResponseDeferredChannelMessageWithSource {
+		t.Errorf("expected a deferred response, got %d: %+v", status, resp)
+	}
+	h.Wait()
+
+	select {
+	case msg := <-edited:
+		if len(msg.Embeds) != 1 || msg.Embeds[0].Title != "Distracted Boyfriend" || msg.Embeds[0].Image == nil {
+			t.Errorf("unexpected deferred message %+v", msg)
+		}
+	default:
+		t.Fatal("expected the original response to be edited")
+	}
+
+	var logged strings.Builder
+	h = discord.NewHandler(publicKey, api.NewClient(), discord.WithDeferAfter(0), discord.WithErrorLog(log.New(&logged, "", 0)))
+	serve(t, h, interaction(t, discord.Interaction{
+		Type: discord.InteractionApplicationCommand,
+		Data: &discord.CommandData{Options: []discord.CommandOption{
+			{Name: discord.TemplateOption, Value: "drake"},
+			{Name: discord.TextOption, Value: "a"},
+		}},
+	}))
+	h.Wait()
+	if !strings.Contains(logged.String(), "no application ID or token") {
+		t.Errorf("expected a missing token to be logged, got %q", logged.String())
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := discord.NewHandler(publicKey, api.NewClient())
+
+	autocomplete := func(value string) []discord.Choice {
+		t.Helper()
+		status, resp := serve(t, h, interaction(t, discord.Interaction{
+			Type: discord.InteractionCommandAutocomplete,
+			Data: &discord.CommandData{Options: []discord.CommandOption{
+				{Name: discord.TemplateOption, Value: value, Focused: true},
+				{Name: discord.TextOption, Value: "ignored"},
+			}},
+		}))
+		if status != http.StatusOK || resp.Type != discord.ResponseAutocompleteResult || resp.Data == nil {
+			t.Fatalf("%q: expected autocomplete results, got %d: %+v", value, status, resp)
+		}
+		return resp.Data.Choices
+	}
+
+	choices := autocomplete("distracted")
+	if len(choices) == 0 || choices[0] != (discord.Choice{Name: "Distracted Boyfriend", Value: "112126428"}) {
+		t.Errorf("expected Distracted Boyfriend first, got %+v", choices)
+	}
+	choices = autocomplete("")
+	if len(choices) != len(imgflipgotest.Memes) || len(choices) > discord.MaxChoices {
+		t.Errorf("expected every template to be suggested, got %+v", choices)
+	}
+	if choices = autocomplete("zzzzzz"); choices == nil || len(choices) != 0 {
+		t.Errorf("expected an empty list of choices, got %+v", choices)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := discord.NewHandler(publicKey, api.NewClient())
+
+	req := command(t, "drake", "a")
+	req.Header.Set("X-Signature-Ed25519", strings.Repeat("00", ed25519.SignatureSize))
+	if status, _ := serve(t, h, req); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a bad signature, got %d", status)
+	}
+	req = command(t, "drake", "a")
+	req.Header.Del("X-Signature-Timestamp")
+	if status, _ := serve(t, h, req); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a missing timestamp, got %d", status)
+	}
+	if len(api.Requests()) != 0 {
+		t.Error("expected unverified commands not to be captioned")
+	}
+
+	body := []byte(`{"type":1}`)
+	header := http.Header{}
+	header.Set("X-Signature-Timestamp", "1700000000")
+	header.Set("X-Signature-Ed25519", hex.EncodeToString(ed25519.Sign(privateKey, append([]byte("1700000000"), body...))))
+	if err := discord.Verify(publicKey, header, body); err != nil {
+		t.Errorf("expected a valid signature to verify, got %v", err)
+	}
+	if err := discord.Verify(publicKey, header, []byte(`{"type":2}`)); !errors.Is(err, discord.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a modified body, got %v", err)
+	}
+	header.Set("X-Signature-Ed25519", "not hex")
+	if err := discord.Verify(publicKey, header, body); !errors.Is(err, discord.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature for a malformed signature, got %v", err)
+	}
+	header.Del("X-Signature-Ed25519")
+	if err := discord.Verify(publicKey, header, body); !errors.Is(err, discord.ErrMissingSignature) {
+		t.Errorf("expected ErrMissingSignature, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package imgflipgo
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	// Register decoders for the formats imgflip serves captioned images in.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// DefaultMaxImageSize is the largest image, in bytes, that a Client will
+// download unless configured otherwise with WithMaxImageSize.
+const DefaultMaxImageSize int64 = 20 << 20
+
+// WithMaxImageSize sets the largest image, in bytes, that the Client will
+// download. Larger images fail with ErrImageTooLarge. If maxBytes is not
+// positive, image size is not limited. Defaults to DefaultMaxImageSize.
+func WithMaxImageSize(maxBytes int64) ClientOption {
+	return func(c *Client) {
+		c.maxImageSize = maxBytes
+	}
+}
+
+// OpenImage starts downloading the image at imageURL, e.g. CaptionResponse.Data.URL,
+// using the Client's HTTP client and user agent. The response must have an
+// image content type, otherwise an error wrapping ErrUnexpectedContentType is
+// returned. Reading more than the Client's maximum image size fails with
+// ErrImageTooLarge. The caller must close the returned reader.
+//
+// Images are not served by the API itself, so the Client's rate limiters and
+// retry policy do not apply.
+func (c *Client) OpenImage(ctx context.Context, imageURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, contextError(ctx, "image", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %s", imageURL, resp.Status)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %w %q", imageURL, ErrUnexpectedContentType, contentType)
+	}
+
+	if c.maxImageSize > 0 && resp.ContentLength > c.maxImageSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading %s: %w: %d bytes", imageURL, ErrImageTooLarge, resp.ContentLength)
+	}
+	if c.maxImageSize <= 0 {
+		return resp.Body, nil
+	}
+	return &limitedBody{ReadCloser: resp.Body, remaining: c.maxImageSize}, nil
+}
+
+// DownloadImage is like OpenImage, but reads the whole image into memory.
+func (c *Client) DownloadImage(ctx context.Context, imageURL string) ([]byte, error) {
+	body, err := c.OpenImage(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, contextError(ctx, "image", err)
+	}
+	return data, nil
+}
+
+// FetchImage is like OpenImage, but decodes the image. JPEG, PNG, and GIF images
+// are supported; only the first frame of an animated GIF is decoded.
+func (c *Client) FetchImage(ctx context.Context, imageURL string) (image.Image, error) {
+	body, err := c.OpenImage(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	img, _, err := image.Decode(body)
+	if err != nil {
+		return nil, contextError(ctx, "image", err)
+	}
+	return img, nil
+}
+
+// Open starts downloading the captioned image with client, or DefaultClient if
+// client is nil. See Client.OpenImage.
+func (cr CaptionResponse) Open(ctx context.Context, client *Client) (io.ReadCloser, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	return client.OpenImage(ctx, cr.Data.URL)
+}
+
+// Download downloads the captioned image like Open. See Client.DownloadImage.
+func (cr CaptionResponse) Download(ctx context.Context, client *Client) ([]byte, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	return client.DownloadImage(ctx, cr.Data.URL)
+}
+
+// Image downloads and decodes the captioned image like Open. See
+// Client.FetchImage.
+func (cr CaptionResponse) Image(ctx context.Context, client *Client) (image.Image, error) {
+	if client == nil {
+		client = DefaultClient
+	}
+	return client.FetchImage(ctx, cr.Data.URL)
+}
+
+// limitedBody fails with ErrImageTooLarge once more than remaining bytes have
+// been read from it.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrImageTooLarge
+	}
+	// Read one byte past the limit, so that an image of exactly the maximum
+	// size is distinguished from a larger one.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrImageTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,160 @@
+package imgflipgo_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func captionForDownload(t *testing.T, client *imgflipgo.Client) imgflipgo.CaptionResponse {
+	t.Helper()
+	req := (&imgflipgo.CaptionRequest{TemplateID: imgflipgotest.Memes[0].ID}).SetTopText("top").SetBottomText("bottom")
+	resp, err := client.CaptionImage(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestFetchImage(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+	resp := captionForDownload(t, client)
+
+	img, err := client.FetchImage(context.Background(), resp.Data.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != imgflipgotest.ImageWidth || img.Bounds().Dy() != imgflipgotest.ImageHeight {
+		t.Errorf("expected a %dx%d image, got %v", imgflipgotest.ImageWidth, imgflipgotest.ImageHeight, img.Bounds())
+	}
+
+	data, err := client.DownloadImage(context.Background(), resp.Data.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if http.DetectContentType(data) != "image/jpeg" {
+		t.Errorf("expected a JPEG, got %s", http.DetectContentType(data))
+	}
+
+	body, err := client.OpenImage(context.Background(), resp.Data.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(streamed) != string(data) {
+		t.Error("expected streamed and downloaded images to match")
+	}
+}
+
+func TestCaptionResponseDownload(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	client := srv.NewClient()
+	resp := captionForDownload(t, client)
+	data, err := resp.Download(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		t.Error("expected image data")
+	}
+	if _, err := resp.Image(context.Background(), client); err != nil {
+		t.Error(err)
+	}
+
+	// The given Client is used, along with its settings.
+	if _, err := resp.Download(context.Background(), srv.NewClient(imgflipgo.WithMaxImageSize(10))); !errors.Is(err, imgflipgo.ErrImageTooLarge) {
+		t.Errorf("expected the given Client's ErrImageTooLarge, got %v", err)
+	}
+
+	// A nil Client falls back to DefaultClient.
+	original := imgflipgo.DefaultClient
+	imgflipgo.DefaultClient = srv.NewClient(imgflipgo.WithMaxImageSize(10))
+	defer func() { imgflipgo.DefaultClient = original }()
+	if _, err := resp.Open(context.Background(), nil); !errors.Is(err, imgflipgo.ErrImageTooLarge) {
+		t.Errorf("expected DefaultClient's ErrImageTooLarge, got %v", err)
+	}
+}
+
+func TestDownloadImageTooLarge(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient(imgflipgo.WithMaxImageSize(10))
+	resp := captionForDownload(t, client)
+
+	// The fake server sends a Content-Length, so this fails up front.
+	_, err := client.DownloadImage(context.Background(), resp.Data.URL)
+	if !errors.Is(err, imgflipgo.ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+
+	// Without a Content-Length, the limit is enforced while reading.
+	chunked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(strings.Repeat("x", 5)))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(strings.Repeat("x", 6)))
+	}))
+	defer chunked.Close()
+
+	client = imgflipgo.NewClient(imgflipgo.WithMaxImageSize(10))
+	body, err := client.OpenImage(context.Background(), chunked.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if !errors.Is(err, imgflipgo.ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+	if len(data) > 10 {
+		t.Errorf("expected at most 10 bytes to be read, got %d", len(data))
+	}
+
+	client = imgflipgo.NewClient(imgflipgo.WithMaxImageSize(11))
+	data, err = client.DownloadImage(context.Background(), chunked.URL)
+	if err != nil {
+		t.Errorf("expected an image of exactly the maximum size to succeed, got %v", err)
+	}
+	if len(data) != 11 {
+		t.Errorf("expected 11 bytes, got %d", len(data))
+	}
+}
+
+func TestDownloadImageErrors(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	client := srv.NewClient()
+
+	_, err := client.DownloadImage(context.Background(), srv.URL+"/get_memes")
+	if !errors.Is(err, imgflipgo.ErrUnexpectedContentType) {
+		t.Errorf("expected ErrUnexpectedContentType, got %v", err)
+	}
+
+	_, err = client.DownloadImage(context.Background(), srv.URL+"/images/missing.jpg")
+	if err == nil {
+		t.Error("expected an error for a missing image")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp := captionForDownload(t, client)
+	_, err = client.FetchImage(ctx, resp.Data.URL)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
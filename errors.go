@@ -0,0 +1,101 @@
+package imgflipgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNilRequest is returned when a nil request is passed to an API call that
+	// requires one.
+	ErrNilRequest = errors.New("nil request provided")
+
+	// ErrInvalidCredentials matches API errors caused by a missing or invalid
+	// imgflip username or password.
+	ErrInvalidCredentials = errors.New("invalid imgflip credentials")
+
+	// ErrTemplateNotFound matches API errors caused by a template ID that does
+	// not exist.
+	ErrTemplateNotFound = errors.New("template not found")
+
+	// ErrImageTooLarge is returned when a downloaded image exceeds the Client's
+	// maximum image size.
+	ErrImageTooLarge = errors.New("image too large")
+
+	// ErrUnexpectedContentType is returned when a downloaded image is served
+	// with a content type that isn't an image.
+	ErrUnexpectedContentType = errors.New("unexpected content type")
+)
+
+// APIError is returned when the Imgflip API responds, but reports that the
+// request failed. Use errors.Is with ErrInvalidCredentials or ErrTemplateNotFound
+// to check for common causes.
+type APIError struct {
+	// Endpoint that returned the error, e.g. "caption_image".
+	Endpoint string
+
+	// HTTP status code of the response.
+	StatusCode int
+
+	// Message is the error_message returned by the API, if any.
+	Message string
+
+	// Body is the raw response body.
+	Body []byte
+}
+
+func newAPIError(endpoint string, resp *response, message string) *APIError {
+	return &APIError{
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		Body:       resp.Body,
+	}
+}
+
+// Error returns the API's error message verbatim, so that it matches the
+// ErrorMsg field of the corresponding response.
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.StatusCode != 0 && e.StatusCode != http.StatusOK {
+		return fmt.Sprintf("%s request was unsuccessful: %d %s", e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%s request was unsuccessful", e.Endpoint)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrInvalidCredentials:
+		return e.StatusCode == http.StatusUnauthorized ||
+			e.StatusCode == http.StatusForbidden ||
+			isInvalidCredentialsMsg(e.Message)
+	case ErrTemplateNotFound:
+		return isTemplateNotFoundMsg(e.Message)
+	}
+	return false
+}
+
+// isInvalidCredentialsMsg reports whether an imgflip error message describes a
+// missing or invalid username or password.
+func isInvalidCredentialsMsg(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "username") ||
+		strings.Contains(msg, "password") ||
+		strings.Contains(msg, "credentials")
+}
+
+// isTemplateNotFoundMsg reports whether an imgflip error message describes a
+// missing or invalid template.
+func isTemplateNotFoundMsg(msg string) bool {
+	msg = strings.ToLower(msg)
+	if !strings.Contains(msg, "template") {
+		return false
+	}
+	return strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "not exist") ||
+		strings.Contains(msg, "invalid")
+}
@@ -0,0 +1,79 @@
+package imgflipgo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func TestAPIErrorInvalidCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":false,"error_message":"Invalid username/password combination"}`)
+	}))
+	defer srv.Close()
+
+	resp, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL)).CaptionImage((&imgflipgo.CaptionRequest{
+		TemplateID: testTemplateID,
+		Username:   "not_a_real_user",
+		Password:   "asdf",
+	}).SetTopText("Top Text"))
+	expectFailure(t, resp, err)
+
+	var apiErr *imgflipgo.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %T", err)
+	}
+	if apiErr.Endpoint != "caption_image" || apiErr.StatusCode != http.StatusOK || len(apiErr.Body) == 0 {
+		t.Errorf("unexpected APIError %+v", apiErr)
+	}
+	if !errors.Is(err, imgflipgo.ErrInvalidCredentials) {
+		t.Error("expected error to match ErrInvalidCredentials")
+	}
+	if errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+		t.Error("did not expect error to match ErrTemplateNotFound")
+	}
+}
+
+func TestAPIErrorServerFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream exploded", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL)).GetMemes()
+	var apiErr *imgflipgo.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusBadGateway || apiErr.Endpoint != "get_memes" {
+		t.Errorf("unexpected APIError %+v", apiErr)
+	}
+}
+
+func TestGetMemesWithResponseUnsuccessful(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"success":false}`)
+	}))
+	defer srv.Close()
+
+	resp, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL)).GetMemesWithResponse()
+	var apiErr *imgflipgo.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %T: %v", err, err)
+	}
+	if resp == nil || resp.Success {
+		t.Fatalf("expected the unsuccessful response to be returned, got %+v", resp)
+	}
+}
+
+func TestErrNilRequest(t *testing.T) {
+	resp, err := imgflipgo.CaptionImage(nil)
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrNilRequest) {
+		t.Fatalf("expected ErrNilRequest, got %v", err)
+	}
+}
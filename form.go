@@ -0,0 +1,323 @@
+package imgflipgo
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formWriter appends application/x-www-form-urlencoded key=value pairs to buf.
+// The request types write their fields with it directly rather than building
+// url.Values, producing exactly the bytes url.Values.Encode would: keys must be
+// written in the order Encode sorts them, and are written as given, so they
+// must already be escaped.
+type formWriter struct {
+	buf   []byte
+	start int
+}
+
+func newFormWriter(dst []byte) formWriter {
+	return formWriter{buf: dst, start: len(dst)}
+}
+
+// key starts a new pair, writing a separator if needed, key, and "=".
+func (w *formWriter) key(key string) {
+	if len(w.buf) > w.start {
+		w.buf = append(w.buf, '&')
+	}
+	w.buf = append(w.buf, key...)
+	w.buf = append(w.buf, '=')
+}
+
+func (w *formWriter) string(key, value string) {
+	w.key(key)
+	w.buf = appendQueryEscape(w.buf, value)
+}
+
+func (w *formWriter) uint(key string, n uint) {
+	w.key(key)
+	w.buf = strconv.AppendUint(w.buf, uint64(n), 10)
+}
+
+func (w *formWriter) bool(key string, b bool) {
+	w.key(key)
+	w.buf = strconv.AppendBool(w.buf, b)
+}
+
+// textBoxes writes each TextBox as boxes[i][field] pairs. Encode sorts keys
+// as strings, so the boxes are written in the order of their indices' decimal
+// strings, and an index comes after every index it is a prefix of, since the
+// "]" that follows it sorts after the digits: 0, 10, 11, 1, 2, ...
+func (w *formWriter) textBoxes(boxes []TextBox) {
+	if len(boxes) > 0 {
+		w.textBox(0, &boxes[0])
+	}
+	for i := 1; i <= 9; i++ {
+		w.textBoxTree(boxes, i)
+	}
+}
+
+// textBoxTree writes box i after the boxes whose indices it prefixes.
+func (w *formWriter) textBoxTree(boxes []TextBox, i int) {
+	if i >= len(boxes) {
+		return
+	}
+	for digit := 0; digit <= 9; digit++ {
+		w.textBoxTree(boxes, i*10+digit)
+	}
+	w.textBox(i, &boxes[i])
+}
+
+func (w *formWriter) textBox(i int, tb *TextBox) {
+	if tb.Color != nil {
+		w.boxKey(i, "color")
+		w.buf = appendColor(w.buf, *tb.Color)
+	}
+	if tb.EndMs != nil {
+		w.boxKey(i, "end_ms")
+		w.buf = strconv.AppendUint(w.buf, uint64(*tb.EndMs), 10)
+	}
+	if tb.Height != nil {
+		w.boxKey(i, "height")
+		w.buf = strconv.AppendUint(w.buf, uint64(*tb.Height), 10)
+	}
+	if tb.OutlineColor != nil {
+		w.boxKey(i, "outline_color")
+		w.buf = appendColor(w.buf, *tb.OutlineColor)
+	}
+	if tb.StartMs != nil {
+		w.boxKey(i, "start_ms")
+		w.buf = strconv.AppendUint(w.buf, uint64(*tb.StartMs), 10)
+	}
+	w.boxKey(i, "text")
+	w.buf = appendQueryEscape(w.buf, tb.Text)
+	if tb.Width != nil {
+		w.boxKey(i, "width")
+		w.buf = strconv.AppendUint(w.buf, uint64(*tb.Width), 10)
+	}
+	if tb.X != nil {
+		w.boxKey(i, "x")
+		w.buf = strconv.AppendUint(w.buf, uint64(*tb.X), 10)
+	}
+	if tb.Y != nil {
+		w.boxKey(i, "y")
+		w.buf = strconv.AppendUint(w.buf, uint64(*tb.Y), 10)
+	}
+}
+
+// boxKey starts a boxes[i][field] pair.
+func (w *formWriter) boxKey(i int, field string) {
+	if len(w.buf) > w.start {
+		w.buf = append(w.buf, '&')
+	}
+	w.buf = append(w.buf, "boxes%5B"...)
+	w.buf = strconv.AppendInt(w.buf, int64(i), 10)
+	w.buf = append(w.buf, "%5D%5B"...)
+	w.buf = append(w.buf, field...)
+	w.buf = append(w.buf, "%5D="...)
+}
+
+// appendColor appends the escaped form of c.String().
+func appendColor(dst []byte, c Color) []byte {
+	dst = append(dst, "%23"...)
+	for n := Color(0xFFFFF); n > 0 && c <= n; n >>= 4 {
+		dst = append(dst, '0')
+	}
+	return strconv.AppendUint(dst, uint64(c), 16)
+}
+
+// appendQueryEscape appends s escaped as by url.QueryEscape.
+func appendQueryEscape(dst []byte, s string) []byte {
+	const upperhex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			dst = append(dst, c)
+		case c == ' ':
+			dst = append(dst, '+')
+		default:
+			dst = append(dst, '%', upperhex[c>>4], upperhex[c&15])
+		}
+	}
+	return dst
+}
+
+// ParseCaptionForm is the inverse of CaptionRequest.CreateHTTPFormBody. It
+// rebuilds a CaptionRequest from a caption_image form: text0, text1, font, and
+// max_font_size set their fields whenever they're present, even if empty, and
+// boxes[i][field] entries set TextBoxes. Other keys are ignored.
+//
+// Parsing is strict. Repeated keys, numbers that aren't unsigned integers,
+// colors that aren't "#rrggbb", unknown text box fields, and malformed text box
+// indices (not decimal, with leading zeros, or not consecutive from 0) are
+// reported in the returned *ValidationError, which lists every problem found.
+// The request itself is not validated; use Validate for that.
+func ParseCaptionForm(form url.Values) (*CaptionRequest, error) {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cr := &CaptionRequest{}
+	boxes := map[int]*TextBox{}
+	v := validator{}
+	for _, key := range keys {
+		values := form[key]
+		if len(values) == 0 {
+			continue
+		}
+		if len(values) > 1 {
+			v.addf("%s: specified %d times", key, len(values))
+			continue
+		}
+		value := values[0]
+
+		switch key {
+		case "template_id":
+			cr.TemplateID = value
+		case "username":
+			cr.Username = value
+		case "password":
+			cr.Password = value
+		case "text0":
+			cr.SetTopText(value)
+		case "text1":
+			cr.SetBottomText(value)
+		case "font":
+			cr.SetFont(Font(value))
+		case "max_font_size":
+			n, err := parseFormUint(value)
+			if err != nil {
+				v.addf("%s: %w", key, err)
+				continue
+			}
+			cr.SetMaxFontSize(n)
+		default:
+			if !strings.HasPrefix(key, "boxes") {
+				continue
+			}
+			i, field, ok := parseBoxKey(key)
+			if !ok {
+				v.addf("%s: malformed text box key, expected boxes[i][field]", key)
+				continue
+			}
+			if boxes[i] == nil {
+				boxes[i] = &TextBox{}
+			}
+			err := setTextBoxFormField(boxes[i], field, value)
+			if err != nil {
+				v.addf("%s: %w", key, err)
+			}
+		}
+	}
+
+	indices := make([]int, 0, len(boxes))
+	for i := range boxes {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	for want, i := range indices {
+		if i != want {
+			v.addf("boxes[%d]: text box indices must be consecutive from 0, but boxes[%d] is missing", i, want)
+			break
+		}
+		cr.TextBoxes = append(cr.TextBoxes, *boxes[i])
+	}
+
+	err := v.err()
+	if err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// parseBoxKey splits a boxes[i][field] key. The index must be a decimal
+// integer without leading zeros.
+func parseBoxKey(key string) (int, string, bool) {
+	rest := strings.TrimPrefix(key, "boxes[")
+	end := strings.Index(rest, "][")
+	if len(rest) == len(key) || end <= 0 || !strings.HasSuffix(rest, "]") {
+		return 0, "", false
+	}
+	index, field := rest[:end], rest[end+2:len(rest)-1]
+	for _, c := range index {
+		if c < '0' || c > '9' {
+			return 0, "", false
+		}
+	}
+	if len(index) > 1 && index[0] == '0' {
+		return 0, "", false
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return 0, "", false
+	}
+	return i, field, true
+}
+
+func setTextBoxFormField(tb *TextBox, field, value string) error {
+	switch field {
+	case "text":
+		tb.Text = value
+		return nil
+	case "color", "outline_color":
+		c, err := parseFormColor(value)
+		if err != nil {
+			return err
+		}
+		if field == "color" {
+			tb.SetColor(c)
+		} else {
+			tb.SetOutlineColor(c)
+		}
+		return nil
+	}
+
+	var dst **uint
+	switch field {
+	case "x":
+		dst = &tb.X
+	case "y":
+		dst = &tb.Y
+	case "width":
+		dst = &tb.Width
+	case "height":
+		dst = &tb.Height
+	case "start_ms":
+		dst = &tb.StartMs
+	case "end_ms":
+		dst = &tb.EndMs
+	default:
+		return fmt.Errorf("unknown text box field %q", field)
+	}
+	n, err := parseFormUint(value)
+	if err != nil {
+		return err
+	}
+	*dst = &n
+	return nil
+}
+
+func parseFormUint(value string) (uint, error) {
+	n, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an unsigned integer", value)
+	}
+	return uint(n), nil
+}
+
+// parseFormColor parses a color as the API sends it, "#rrggbb".
+func parseFormColor(value string) (Color, error) {
+	if len(value) == 7 && value[0] == '#' {
+		n, err := strconv.ParseUint(value[1:], 16, 32)
+		if err == nil {
+			return Color(n), nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q, expected #rrggbb", ErrInvalidColor, value)
+}
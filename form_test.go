@@ -0,0 +1,365 @@
+package imgflipgo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+// formEncoder is implemented by every request type.
+type formEncoder interface {
+	AppendForm(dst []byte) []byte
+	CreateHTTPFormBody() (url.Values, error)
+}
+
+type goldenForm struct {
+	name string
+	req  formEncoder
+	want string
+}
+
+// goldenForms are requests and their encodings as produced by the previous
+// gorilla/schema and url.Values based encoders, which the direct encoder must
+// match exactly.
+func goldenForms() []goldenForm {
+	str := func(s string) *string { return &s }
+	font := func(f imgflipgo.Font) *imgflipgo.Font { return &f }
+	num := func(n uint) *uint { return &n }
+
+	// More than ten boxes, so that "boxes[10]" sorts before "boxes[1]".
+	boxes := make([]imgflipgo.TextBox, 12)
+	for i := range boxes {
+		boxes[i].Text = "box " + string(rune('a'+i))
+	}
+	boxes[0].Text = ""
+	boxes[1].SetX(0).SetY(10).SetWidth(200).SetHeight(100)
+	boxes[2].SetColor(0xFFA500).SetOutlineColor(0)
+	boxes[3].Text = "[brackets] & ampersands = equals; 100% + plus"
+	boxes[10].SetColor(imgflipgo.MaxColor).SetX(1).SetY(2).SetWidth(3).SetHeight(4)
+	boxes[11].Text = "ünïcödé / ~_-. \"quotes\" #hash"
+
+	gifBoxes := []imgflipgo.TextBox{
+		*(&imgflipgo.TextBox{Text: "first"}).SetStartMs(0).SetEndMs(1500),
+		*(&imgflipgo.TextBox{Text: "second"}).SetStartMs(1500).SetColor(0x00FF00),
+	}
+
+	return []goldenForm{
+		{"caption/zero", imgflipgo.CaptionRequest{}, ""},
+		{"caption/template", imgflipgo.CaptionRequest{TemplateID: "181913649"}, "template_id=181913649"},
+		{
+			"caption/text",
+			imgflipgo.CaptionRequest{
+				TemplateID:    "181913649",
+				Username:      "user",
+				Password:      "p@ss w&rd+1",
+				TopText:       str("Top text: 100% + more?"),
+				BottomText:    str("ünïcödé / ~_-. <b>"),
+				Font:          font(imgflipgo.FontImpact),
+				MaxFontSizePx: num(40),
+			},
+			"font=impact&max_font_size=40&password=p%40ss+w%26rd%2B1&template_id=181913649" +
+				"&text0=Top+text%3A+100%25+%2B+more%3F&text1=%C3%BCn%C3%AFc%C3%B6d%C3%A9+%2F+~_-.+%3Cb%3E&username=user",
+		},
+		{
+			"caption/empty pointers",
+			imgflipgo.CaptionRequest{
+				TemplateID:    "1",
+				TopText:       str(""),
+				BottomText:    str(""),
+				Font:          font(""),
+				MaxFontSizePx: num(0),
+			},
+			"font=&max_font_size=0&template_id=1&text0=&text1=",
+		},
+		{
+			"caption/boxes",
+			imgflipgo.CaptionRequest{
+				TemplateID: "112126428",
+				Username:   "user",
+				Password:   "pass",
+				TopText:    str("ignored"),
+				Font:       font(imgflipgo.FontArial),
+				TextBoxes:  boxes,
+			},
+			"boxes%5B0%5D%5Btext%5D=" +
+				"&boxes%5B10%5D%5Bcolor%5D=%23ffffff&boxes%5B10%5D%5Bheight%5D=4&boxes%5B10%5D%5Btext%5D=box+k" +
+				"&boxes%5B10%5D%5Bwidth%5D=3&boxes%5B10%5D%5Bx%5D=1&boxes%5B10%5D%5By%5D=2" +
+				"&boxes%5B11%5D%5Btext%5D=%C3%BCn%C3%AFc%C3%B6d%C3%A9+%2F+~_-.+%22quotes%22+%23hash" +
+				"&boxes%5B1%5D%5Bheight%5D=100&boxes%5B1%5D%5Btext%5D=box+b&boxes%5B1%5D%5Bwidth%5D=200" +
+				"&boxes%5B1%5D%5Bx%5D=0&boxes%5B1%5D%5By%5D=10" +
+				"&boxes%5B2%5D%5Bcolor%5D=%23ffa500&boxes%5B2%5D%5Boutline_color%5D=%23000000&boxes%5B2%5D%5Btext%5D=box+c" +
+				"&boxes%5B3%5D%5Btext%5D=%5Bbrackets%5D+%26+ampersands+%3D+equals%3B+100%25+%2B+plus" +
+				"&boxes%5B4%5D%5Btext%5D=box+e&boxes%5B5%5D%5Btext%5D=box+f&boxes%5B6%5D%5Btext%5D=box+g" +
+				"&boxes%5B7%5D%5Btext%5D=box+h&boxes%5B8%5D%5Btext%5D=box+i&boxes%5B9%5D%5Btext%5D=box+j" +
+				"&font=arial&password=pass&template_id=112126428&text0=ignored&username=user",
+		},
+		{"gif/zero", imgflipgo.CaptionGifRequest{}, ""},
+		{
+			"gif/boxes",
+			imgflipgo.CaptionGifRequest{
+				TemplateID: "1",
+				Username:   "user",
+				Password:   "pass",
+				TextBoxes:  gifBoxes,
+			},
+			"boxes%5B0%5D%5Bend_ms%5D=1500&boxes%5B0%5D%5Bstart_ms%5D=0&boxes%5B0%5D%5Btext%5D=first" +
+				"&boxes%5B1%5D%5Bcolor%5D=%2300ff00&boxes%5B1%5D%5Bstart_ms%5D=1500&boxes%5B1%5D%5Btext%5D=second" +
+				"&password=pass&template_id=1&username=user",
+		},
+		{"automeme/zero", imgflipgo.AutoMemeRequest{}, "text="},
+		{
+			"automeme/full",
+			imgflipgo.AutoMemeRequest{
+				Username:    "user",
+				Password:    "pass",
+				Text:        "one does not simply walk into mordor",
+				NoWatermark: true,
+			},
+			"no_watermark=true&password=pass&text=one+does+not+simply+walk+into+mordor&username=user",
+		},
+		{"ai-meme/zero", imgflipgo.AIMemeRequest{}, ""},
+		{
+			"ai-meme/full",
+			imgflipgo.AIMemeRequest{
+				Username:    "user",
+				Password:    "pass",
+				Model:       imgflipgo.AIModelOpenAI,
+				TemplateID:  "181913649",
+				PrefixText:  "when the tests pass",
+				NoWatermark: true,
+			},
+			"model=openai&no_watermark=true&password=pass&prefix_text=when+the+tests+pass&template_id=181913649&username=user",
+		},
+		{"get-meme/zero", imgflipgo.GetMemeRequest{}, "template_id="},
+		{
+			"get-meme/full",
+			imgflipgo.GetMemeRequest{Username: "user", Password: "pass", TemplateID: "181913649"},
+			"password=pass&template_id=181913649&username=user",
+		},
+		{"search/zero", imgflipgo.SearchRequest{}, "query="},
+		{
+			"search/full",
+			imgflipgo.SearchRequest{
+				Username:    "user",
+				Password:    "pass",
+				Query:       "distracted bf & co",
+				IncludeNSFW: true,
+			},
+			"include_nsfw=1&password=pass&query=distracted+bf+%26+co&username=user",
+		},
+	}
+}
+
+func TestAppendFormGolden(t *testing.T) {
+	for _, test := range goldenForms() {
+		if got := string(test.req.AppendForm(nil)); got != test.want {
+			t.Errorf("%s: AppendForm\nexpected %s\ngot      %s", test.name, test.want, got)
+		}
+
+		// Appending must leave what's already in the buffer alone.
+		prefix := "prefix&"
+		if got := string(test.req.AppendForm([]byte(prefix))); got != prefix+test.want {
+			t.Errorf("%s: expected AppendForm to append to the buffer, got %s", test.name, got)
+		}
+	}
+}
+
+func TestCreateHTTPFormBodyGolden(t *testing.T) {
+	for _, test := range goldenForms() {
+		form, err := test.req.CreateHTTPFormBody()
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if encoded := form.Encode(); encoded != test.want {
+			t.Errorf("%s: CreateHTTPFormBody\nexpected %s\ngot      %s", test.name, test.want, encoded)
+		}
+	}
+}
+
+func TestAppendFormEscaping(t *testing.T) {
+	text := make([]byte, 256)
+	for i := range text {
+		text[i] = byte(i)
+	}
+	want := "text=" + url.QueryEscape(string(text))
+	if got := string(imgflipgo.AutoMemeRequest{Text: string(text)}.AppendForm(nil)); got != want {
+		t.Errorf("expected text to be escaped as by url.QueryEscape\nexpected %s\ngot      %s", want, got)
+	}
+}
+
+func TestParseCaptionForm(t *testing.T) {
+	for _, test := range goldenForms() {
+		req, ok := test.req.(imgflipgo.CaptionRequest)
+		if !ok {
+			continue
+		}
+		form, err := req.CreateHTTPFormBody()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := imgflipgo.ParseCaptionForm(form)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*parsed, req) {
+			t.Errorf("%s: expected the request to round trip\nexpected %+v\ngot      %+v", test.name, req, *parsed)
+		}
+	}
+
+	form := url.Values{
+		"template_id":             {"1"},
+		"boxes[0][text]":          {"a"},
+		"boxes[0][color]":         {"#FFA500"},
+		"boxes[1][text]":          {"b"},
+		"boxes[1][outline_color]": {"#000000"},
+		"boxes[1][x]":             {"1"},
+		"unrelated":               {"ignored"},
+	}
+	parsed, err := imgflipgo.ParseCaptionForm(form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.TextBoxes) != 2 || *parsed.TextBoxes[0].Color != 0xFFA500 || *parsed.TextBoxes[1].X != 1 {
+		t.Errorf("unexpected text boxes %+v", parsed.TextBoxes)
+	}
+	if parsed.TopText != nil || parsed.Font != nil || parsed.MaxFontSizePx != nil {
+		t.Errorf("expected absent fields to be nil, got %+v", parsed)
+	}
+}
+
+func TestParseCaptionFormInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		form    url.Values
+		problem string
+	}{
+		{"repeated key", url.Values{"text0": {"a", "b"}}, "text0: specified 2 times"},
+		{"max font size", url.Values{"max_font_size": {"-1"}}, "not an unsigned integer"},
+		{"non-numeric index", url.Values{"boxes[a][text]": {"a"}}, "malformed text box key"},
+		{"negative index", url.Values{"boxes[-1][text]": {"a"}}, "malformed text box key"},
+		{"leading zero", url.Values{"boxes[01][text]": {"a"}}, "malformed text box key"},
+		{"empty index", url.Values{"boxes[][text]": {"a"}}, "malformed text box key"},
+		{"overflowing index", url.Values{"boxes[99999999999999999999][text]": {"a"}}, "malformed text box key"},
+		{"missing field", url.Values{"boxes[0]": {"a"}}, "malformed text box key"},
+		{"trailing garbage", url.Values{"boxes[0][text]x": {"a"}}, "malformed text box key"},
+		{"gap", url.Values{"boxes[0][text]": {"a"}, "boxes[2][text]": {"c"}}, "but boxes[1] is missing"},
+		{"unknown field", url.Values{"boxes[0][size]": {"1"}}, `unknown text box field "size"`},
+		{"bad dimension", url.Values{"boxes[0][width]": {"wide"}}, `"wide" is not an unsigned integer`},
+		{"named color", url.Values{"boxes[0][color]": {"orange"}}, "expected #rrggbb"},
+		{"short color", url.Values{"boxes[0][color]": {"#fa0"}}, "expected #rrggbb"},
+	}
+	for _, test := range tests {
+		_, err := imgflipgo.ParseCaptionForm(test.form)
+		if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+			t.Errorf("%s: expected ErrInvalidRequest, got %v", test.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("%s: expected %q, got %v", test.name, test.problem, err)
+		}
+	}
+
+	_, err := imgflipgo.ParseCaptionForm(url.Values{"boxes[0][color]": {"#gggggg"}, "max_font_size": {"big"}})
+	valErr := &imgflipgo.ValidationError{}
+	if !errors.As(err, &valErr) || len(valErr.Problems) != 2 {
+		t.Errorf("expected every problem to be reported, got %v", err)
+	}
+	if !errors.Is(err, imgflipgo.ErrInvalidColor) {
+		t.Errorf("expected ErrInvalidColor, got %v", err)
+	}
+}
+
+func TestAppendFormAllocs(t *testing.T) {
+	req := benchmarkCaptionRequest()
+	buf := make([]byte, 0, 1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = req.AppendForm(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("expected AppendForm not to allocate, got %v allocations", allocs)
+	}
+}
+
+// benchmarkCaptionRequest is a typical request with a few styled text boxes.
+func benchmarkCaptionRequest() imgflipgo.CaptionRequest {
+	req := imgflipgo.CaptionRequest{TemplateID: "181913649", Username: "user", Password: "pass"}
+	req.SetFont(imgflipgo.FontImpact).SetMaxFontSize(40)
+	for i := 0; i < 4; i++ {
+		tb := imgflipgo.TextBox{Text: "some caption text"}
+		tb.SetColor(imgflipgo.MaxColor).SetOutlineColor(0)
+		req.TextBoxes = append(req.TextBoxes, tb)
+	}
+	return req
+}
+
+// urlValuesForm encodes benchmarkCaptionRequest field by field with url.Values,
+// the way forms are usually built, as a baseline for AppendForm.
+func urlValuesForm(req imgflipgo.CaptionRequest) string {
+	form := url.Values{}
+	form.Set("template_id", req.TemplateID)
+	form.Set("username", req.Username)
+	form.Set("password", req.Password)
+	form.Set("font", string(*req.Font))
+	form.Set("max_font_size", strconv.FormatUint(uint64(*req.MaxFontSizePx), 10))
+	for i, tb := range req.TextBoxes {
+		prefix := "boxes[" + strconv.Itoa(i) + "]"
+		form.Set(prefix+"[text]", tb.Text)
+		form.Set(prefix+"[color]", fmt.Sprintf("#%06x", uint(*tb.Color)))
+		form.Set(prefix+"[outline_color]", fmt.Sprintf("#%06x", uint(*tb.OutlineColor)))
+	}
+	return form.Encode()
+}
+
+func TestURLValuesFormBaseline(t *testing.T) {
+	req := benchmarkCaptionRequest()
+	if got, want := urlValuesForm(req), string(req.AppendForm(nil)); got != want {
+		t.Errorf("expected the baseline to encode the same form\nwant %s\ngot  %s", want, got)
+	}
+}
+
+// BenchmarkAppendForm and BenchmarkURLValuesForm compare AppendForm with
+// building the same form with url.Values, e.g.
+//
+//	go test -run '^$' -bench 'AppendForm|URLValuesForm' -benchmem
+//
+// The gorilla/schema encoder that AppendForm replaced is no longer in the tree,
+// so it can't be benchmarked here. The figures quoted for it in the commit that
+// introduced AppendForm, about 30µs, 7664 B, and 159 allocations per op, were
+// measured with BenchmarkCreateHTTPFormBody while CreateHTTPFormBody still used
+// it.
+func BenchmarkAppendForm(b *testing.B) {
+	req := benchmarkCaptionRequest()
+	buf := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf = req.AppendForm(buf[:0])
+	}
+}
+
+func BenchmarkURLValuesForm(b *testing.B) {
+	req := benchmarkCaptionRequest()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = urlValuesForm(req)
+	}
+}
+
+func BenchmarkCreateHTTPFormBody(b *testing.B) {
+	req := benchmarkCaptionRequest()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		form, err := req.CreateHTTPFormBody()
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = form.Encode()
+	}
+}
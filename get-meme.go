@@ -0,0 +1,128 @@
+package imgflipgo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+const GetMemeEndpoint string = DefaultBaseURL + "/" + getMemePath
+
+type MemeResponse struct {
+	Success bool `json:"success,omitempty"`
+	Data    struct {
+		Meme Meme `json:"meme,omitempty"`
+	} `json:"data,omitempty"`
+
+	ErrorMsg string `json:"error_message,omitempty"`
+}
+
+// GetMemeRequest specifies parameters for the get_meme endpoint.
+type GetMemeRequest struct {
+	// Username of a valid imgflip account. The get_meme endpoint requires
+	// authentication.
+	Username string `json:"username,omitempty"`
+
+	// Password for the imgflip account.
+	Password string `json:"password,omitempty"`
+
+	// The template to look up.
+	TemplateID string `json:"template_id,omitempty"`
+}
+
+// GetMemeOption sets an optional GetMemeRequest parameter.
+type GetMemeOption func(*GetMemeRequest)
+
+// GetMemeCredentials sets the imgflip account used for the request. If not
+// provided, the Client's credentials are used.
+func GetMemeCredentials(username, password string) GetMemeOption {
+	return func(gr *GetMemeRequest) {
+		gr.Username = username
+		gr.Password = password
+	}
+}
+
+// AppendForm appends the application/x-www-form-urlencoded encoding of gr to
+// dst and returns the extended buffer, like CaptionRequest.AppendForm.
+func (gr GetMemeRequest) AppendForm(dst []byte) []byte {
+	w := newFormWriter(dst)
+	if gr.Password != "" {
+		w.string("password", gr.Password)
+	}
+	w.string("template_id", gr.TemplateID)
+	if gr.Username != "" {
+		w.string("username", gr.Username)
+	}
+	return w.buf
+}
+
+func (gr GetMemeRequest) CreateHTTPFormBody() (url.Values, error) {
+	return url.ParseQuery(string(gr.AppendForm(nil)))
+}
+
+// GetMemeWithResponse wraps the get_meme endpoint using DefaultClient.
+func GetMemeWithResponse(templateID string, opts ...GetMemeOption) (*MemeResponse, error) {
+	return DefaultClient.GetMemeWithResponse(templateID, opts...)
+}
+
+// GetMemeWithResponseContext is like GetMemeWithResponse, but the request is bound to ctx.
+func GetMemeWithResponseContext(ctx context.Context, templateID string, opts ...GetMemeOption) (*MemeResponse, error) {
+	return DefaultClient.GetMemeWithResponseContext(ctx, templateID, opts...)
+}
+
+// GetMeme returns the template with the given ID using DefaultClient.
+func GetMeme(templateID string, opts ...GetMemeOption) (Meme, error) {
+	return DefaultClient.GetMeme(templateID, opts...)
+}
+
+// GetMemeContext is like GetMeme, but the request is bound to ctx.
+func GetMemeContext(ctx context.Context, templateID string, opts ...GetMemeOption) (Meme, error) {
+	return DefaultClient.GetMemeContext(ctx, templateID, opts...)
+}
+
+// GetMemeWithResponse wraps the get_meme endpoint. The endpoint requires
+// authentication, so the Client's credentials are sent with the request unless
+// others are given with GetMemeCredentials. If the API reports that the request
+// failed, the response is returned along with an *APIError.
+func (c *Client) GetMemeWithResponse(templateID string, opts ...GetMemeOption) (*MemeResponse, error) {
+	return c.GetMemeWithResponseContext(context.Background(), templateID, opts...)
+}
+
+// GetMemeWithResponseContext is like GetMemeWithResponse, but the request is
+// bound to ctx.
+func (c *Client) GetMemeWithResponseContext(ctx context.Context, templateID string, opts ...GetMemeOption) (*MemeResponse, error) {
+	req := GetMemeRequest{TemplateID: templateID}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	req.Username, req.Password = c.credentials(req.Username, req.Password)
+
+	memeResp := MemeResponse{}
+	resp, err := c.call(ctx, getMemePath, req.AppendForm(nil), &memeResp)
+	if err != nil {
+		return nil, err
+	}
+	if !memeResp.Success {
+		return &memeResp, newAPIError(getMemePath, resp, memeResp.ErrorMsg)
+	}
+
+	return &memeResp, nil
+}
+
+// GetMeme returns the template with the given ID. If no such template exists,
+// the returned error wraps ErrTemplateNotFound.
+func (c *Client) GetMeme(templateID string, opts ...GetMemeOption) (Meme, error) {
+	return c.GetMemeContext(context.Background(), templateID, opts...)
+}
+
+// GetMemeContext is like GetMeme, but the request is bound to ctx.
+func (c *Client) GetMemeContext(ctx context.Context, templateID string, opts ...GetMemeOption) (Meme, error) {
+	memeResp, err := c.GetMemeWithResponseContext(ctx, templateID, opts...)
+	if err != nil {
+		return Meme{}, err
+	}
+	if memeResp.Data.Meme.ID == "" {
+		return Meme{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, templateID)
+	}
+	return memeResp.Data.Meme, nil
+}
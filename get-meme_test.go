@@ -0,0 +1,66 @@
+package imgflipgo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func newGetMemeServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/get_meme" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.PostFormValue("template_id") != testTemplateID {
+			fmt.Fprint(w, `{"success":false,"error_message":"Template not found"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"success":true,"data":{"meme":{"id":"%s","name":"Drake Hotline Bling","width":1200,"height":1200,"box_count":2}}}`, testTemplateID)
+	}))
+}
+
+func TestGetMeme(t *testing.T) {
+	srv := newGetMemeServer(t)
+	defer srv.Close()
+
+	meme, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL)).GetMeme(testTemplateID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meme.ID != testTemplateID || meme.BoxCount != 2 || meme.Width != 1200 {
+		t.Fatalf("unexpected meme %+v", meme)
+	}
+}
+
+func TestGetMemeNotFound(t *testing.T) {
+	srv := newGetMemeServer(t)
+	defer srv.Close()
+
+	_, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL)).GetMeme("0")
+	if !errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestGetMemeCredentials(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	username, password := srv.Credentials()
+	client := srv.NewClient(imgflipgo.WithCredentials("wrong", "wrong"))
+
+	if _, err := client.GetMeme(imgflipgotest.Memes[0].ID); !errors.Is(err, imgflipgo.ErrInvalidCredentials) {
+		t.Fatalf("expected the Client's credentials to be rejected, got %v", err)
+	}
+	meme, err := client.GetMeme(imgflipgotest.Memes[0].ID, imgflipgo.GetMemeCredentials(username, password))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meme.ID != imgflipgotest.Memes[0].ID {
+		t.Errorf("unexpected meme %+v", meme)
+	}
+}
@@ -1,13 +1,10 @@
 package imgflipgo
 
 import (
-	"encoding/json"
-	"errors"
-	"io"
-	"net/http"
+	"context"
 )
 
-const GetMemesEndpoint string = "https://api.imgflip.com/get_memes"
+const GetMemesEndpoint string = DefaultBaseURL + "/" + getMemesPath
 
 type Meme struct {
 	ID     string `json:"id,omitempty"`
@@ -25,42 +22,63 @@ type MemesResponse struct {
 	Data    struct {
 		Memes []Meme `json:"memes,omitempty"`
 	} `json:"data,omitempty"`
+
+	ErrorMsg string `json:"error_message,omitempty"`
 }
 
+// GetMemesWithResponse wraps the get_memes endpoint using DefaultClient.
 func GetMemesWithResponse() (*MemesResponse, error) {
-	resp, err := http.Get(GetMemesEndpoint)
-	if err != nil {
-		return nil, err
-	}
-	if resp == nil {
-		return nil, errors.New("nil response received")
-	}
-	defer resp.Body.Close()
+	return DefaultClient.GetMemesWithResponse()
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+// GetMemesWithResponseContext is like GetMemesWithResponse, but the request is bound to ctx.
+func GetMemesWithResponseContext(ctx context.Context) (*MemesResponse, error) {
+	return DefaultClient.GetMemesWithResponseContext(ctx)
+}
+
+// GetMemes returns the memes listed by the get_memes endpoint using DefaultClient.
+func GetMemes() ([]Meme, error) {
+	return DefaultClient.GetMemes()
+}
 
+// GetMemesContext is like GetMemes, but the request is bound to ctx.
+func GetMemesContext(ctx context.Context) ([]Meme, error) {
+	return DefaultClient.GetMemesContext(ctx)
+}
+
+// GetMemesWithResponse wraps the get_memes endpoint.
+func (c *Client) GetMemesWithResponse() (*MemesResponse, error) {
+	return c.GetMemesWithResponseContext(context.Background())
+}
+
+// GetMemesWithResponseContext is like GetMemesWithResponse, but the request is
+// bound to ctx. If ctx is canceled or its deadline passes before the request
+// completes, the returned error wraps ctx.Err(). If the API reports that the
+// request failed, the response is returned along with an *APIError.
+func (c *Client) GetMemesWithResponseContext(ctx context.Context) (*MemesResponse, error) {
 	memesResp := MemesResponse{}
-	err = json.Unmarshal(body, &memesResp)
+	resp, err := c.call(ctx, getMemesPath, nil, &memesResp)
 	if err != nil {
 		return nil, err
 	}
+	if !memesResp.Success {
+		return &memesResp, newAPIError(getMemesPath, resp, memesResp.ErrorMsg)
+	}
 
-	return &memesResp, err
+	return &memesResp, nil
 }
 
-func GetMemes() ([]Meme, error) {
-	memesResp, err := GetMemesWithResponse()
+// GetMemes returns the memes listed by the get_memes endpoint, or an error if
+// the request was unsuccessful.
+func (c *Client) GetMemes() ([]Meme, error) {
+	return c.GetMemesContext(context.Background())
+}
+
+// GetMemesContext is like GetMemes, but the request is bound to ctx.
+func (c *Client) GetMemesContext(ctx context.Context) ([]Meme, error) {
+	memesResp, err := c.GetMemesWithResponseContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if memesResp == nil {
-		return nil, errors.New("nil response received")
-	}
-	if !memesResp.Success {
-		return nil, errors.New("request was unsuccessful")
-	}
 	return memesResp.Data.Memes, nil
 }
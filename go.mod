@@ -1,9 +1,11 @@
 module github.com/Kardbord/imgflipgo/v2
 
-go 1.17
+go 1.18
 
 require (
-	github.com/fatih/structtag v1.2.0
-	github.com/gorilla/schema v1.4.1
 	github.com/joho/godotenv v1.5.1
+	golang.org/x/image v0.18.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/text v0.16.0 // indirect
@@ -0,0 +1,22 @@
+package imgflipgotest
+
+import "github.com/Kardbord/imgflipgo/v2"
+
+// Memes is the catalog that a Server is seeded with. Callers must not modify it;
+// use Server.SetMemes to serve a different catalog.
+var Memes = []imgflipgo.Meme{
+	{ID: "181913649", Name: "Drake Hotline Bling", URL: "https://i.imgflip.com/30b1gx.jpg", Width: 1200, Height: 1200, BoxCount: 2},
+	{ID: "87743020", Name: "Two Buttons", URL: "https://i.imgflip.com/1g8my4.jpg", Width: 600, Height: 908, BoxCount: 3},
+	{ID: "112126428", Name: "Distracted Boyfriend", URL: "https://i.imgflip.com/1ur9b0.jpg", Width: 1200, Height: 800, BoxCount: 3},
+	{ID: "124822590", Name: "Left Exit 12 Off Ramp", URL: "https://i.imgflip.com/22bdq6.jpg", Width: 804, Height: 767, BoxCount: 3},
+	{ID: "129242436", Name: "Change My Mind", URL: "https://i.imgflip.com/24y43o.jpg", Width: 482, Height: 361, BoxCount: 2},
+	{ID: "217743513", Name: "UNO Draw 25 Cards", URL: "https://i.imgflip.com/3lmzyx.jpg", Width: 500, Height: 494, BoxCount: 2},
+	{ID: "131087935", Name: "Running Away Balloon", URL: "https://i.imgflip.com/261o3j.jpg", Width: 761, Height: 1024, BoxCount: 5},
+	{ID: "188390779", Name: "Woman Yelling At Cat", URL: "https://i.imgflip.com/345v97.jpg", Width: 680, Height: 438, BoxCount: 2},
+	{ID: "438680", Name: "Batman Slapping Robin", URL: "https://i.imgflip.com/9ehk.jpg", Width: 400, Height: 387, BoxCount: 2},
+	{ID: "93895088", Name: "Expanding Brain", URL: "https://i.imgflip.com/1jwhww.jpg", Width: 857, Height: 1202, BoxCount: 4},
+	{ID: "102156234", Name: "Mocking Spongebob", URL: "https://i.imgflip.com/1otk96.jpg", Width: 502, Height: 353, BoxCount: 2},
+	{ID: "97984", Name: "Disaster Girl", URL: "https://i.imgflip.com/23ls.jpg", Width: 500, Height: 375, BoxCount: 2},
+	{ID: "61579", Name: "One Does Not Simply", URL: "https://i.imgflip.com/1bij.jpg", Width: 568, Height: 335, BoxCount: 2},
+	{ID: "61544", Name: "Success Kid", URL: "https://i.imgflip.com/1bhk.jpg", Width: 500, Height: 500, BoxCount: 2},
+}
@@ -0,0 +1,468 @@
+// Package imgflipgotest provides a fake Imgflip API server for testing code
+// that uses imgflipgo without touching the network or an imgflip account.
+package imgflipgotest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+// Credentials accepted by a new Server.
+const (
+	DefaultUsername = "imgflipgotest"
+	DefaultPassword = "imgflipgotest"
+)
+
+// Error messages returned by a Server, modeled on those returned by the real API.
+const (
+	ErrMsgInvalidCredentials = "Invalid username/password combination"
+	ErrMsgTemplateNotFound   = "Invalid template_id: template not found"
+	ErrMsgNoTexts            = "No texts specified. Remember, API request params are http parameters not JSON."
+	ErrMsgNoText             = "No text specified"
+	ErrMsgNoQuery            = "No query specified"
+	ErrMsgTooManyBoxes       = "Too many boxes specified"
+	ErrMsgMalformedBoxes     = "Malformed boxes parameter"
+)
+
+// Request is a request received by a Server.
+type Request struct {
+	// Endpoint the request was sent to, e.g. "caption_image".
+	Endpoint string
+
+	// Header holds the request headers.
+	Header http.Header
+
+	// Form is the parsed request form.
+	Form url.Values
+
+	// TextBoxes parsed from the boxes[i][...] form fields, if any.
+	TextBoxes []imgflipgo.TextBox
+}
+
+// Server is a fake Imgflip API backed by an httptest.Server. It implements
+// get_memes, get_meme, search_memes, caption_image, caption_gif, automeme, and
+// ai_meme, serves a placeholder image at the URL of each caption it creates, and
+// is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	username string
+	password string
+	memes    []imgflipgo.Meme
+	modified time.Time
+	requests []Request
+	captions int
+
+	failures   int
+	failStatus int
+}
+
+// NewServer starts a Server seeded with Memes that accepts DefaultUsername and
+// DefaultPassword. The caller must call Close when finished with it.
+func NewServer() *Server {
+	s := &Server{
+		username: DefaultUsername,
+		password: DefaultPassword,
+		memes:    Memes,
+		modified: time.Now().UTC().Truncate(time.Second),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/get_memes", s.handleGetMemes)
+	mux.HandleFunc("/get_meme", s.handleGetMeme)
+	mux.HandleFunc("/search_memes", s.handleSearchMemes)
+	mux.HandleFunc("/caption_image", s.handleCaptionImage)
+	mux.HandleFunc("/caption_gif", s.handleCaptionGif)
+	mux.HandleFunc("/automeme", s.handleAutoMeme)
+	mux.HandleFunc("/ai_meme", s.handleAIMeme)
+	mux.HandleFunc("/images/", s.handleImage)
+	s.Server = httptest.NewServer(s.injectFailures(mux))
+
+	return s
+}
+
+// NewClient returns an imgflipgo.Client pointed at the Server and configured with
+// its credentials. Any opts are applied afterwards, and may override these.
+func (s *Server) NewClient(opts ...imgflipgo.ClientOption) *imgflipgo.Client {
+	username, password := s.Credentials()
+	defaults := []imgflipgo.ClientOption{
+		imgflipgo.WithBaseURL(s.URL),
+		imgflipgo.WithHTTPClient(s.Client()),
+		imgflipgo.WithCredentials(username, password),
+	}
+	return imgflipgo.NewClient(append(defaults, opts...)...)
+}
+
+// Credentials returns the username and password the Server accepts.
+func (s *Server) Credentials() (string, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.username, s.password
+}
+
+// SetCredentials changes the username and password the Server accepts.
+func (s *Server) SetCredentials(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.username = username
+	s.password = password
+}
+
+// SetMemes replaces the catalog the Server serves.
+func (s *Server) SetMemes(memes []imgflipgo.Meme) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memes = append([]imgflipgo.Meme(nil), memes...)
+	s.modified = time.Now().UTC().Truncate(time.Second)
+}
+
+// Requests returns every request the Server has received, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// FailNext makes the Server respond to the next n requests with statusCode,
+// e.g. to exercise retries. Failed requests are not recorded.
+func (s *Server) FailNext(n int, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failStatus = statusCode
+}
+
+func (s *Server) injectFailures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		fail := s.failures > 0
+		status := s.failStatus
+		if fail {
+			s.failures--
+		}
+		s.mu.Unlock()
+
+		if fail {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type errorResponse struct {
+	Success  bool   `json:"success"`
+	ErrorMsg string `json:"error_message"`
+}
+
+type captionData struct {
+	URL        string   `json:"url"`
+	PageURL    string   `json:"page_url"`
+	TemplateID string   `json:"template_id,omitempty"`
+	Texts      []string `json:"texts,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, msg string) {
+	writeJSON(w, errorResponse{Success: false, ErrorMsg: msg})
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, struct {
+		Success bool        `json:"success"`
+		Data    interface{} `json:"data"`
+	}{Success: true, Data: data})
+}
+
+// record parses and records r, returning the recorded Request. If the boxes in
+// the form are malformed, an error message is returned instead.
+func (s *Server) record(endpoint string, r *http.Request) (Request, string) {
+	r.ParseForm()
+	req := Request{Endpoint: endpoint, Header: r.Header, Form: r.Form}
+	textBoxes, errMsg := parseTextBoxes(r.Form)
+	req.TextBoxes = textBoxes
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	return req, errMsg
+}
+
+func (s *Server) authorized(form url.Values) bool {
+	username, password := s.Credentials()
+	return form.Get("username") == username && form.Get("password") == password
+}
+
+func (s *Server) findMeme(templateID string) (imgflipgo.Meme, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, meme := range s.memes {
+		if meme.ID == templateID {
+			return meme, true
+		}
+	}
+	return imgflipgo.Meme{}, false
+}
+
+func (s *Server) newCaption(ext string) captionData {
+	s.mu.Lock()
+	s.captions++
+	id := strconv.FormatInt(int64(s.captions), 36)
+	s.mu.Unlock()
+	return captionData{
+		URL:     fmt.Sprintf("%s/images/%s.%s", s.URL, id, ext),
+		PageURL: fmt.Sprintf("%s/i/%s", s.URL, id),
+	}
+}
+
+// handleGetMemes serves the catalog with ETag and Last-Modified validators, and
+// honors conditional requests using them.
+func (s *Server) handleGetMemes(w http.ResponseWriter, r *http.Request) {
+	s.record("get_memes", r)
+
+	s.mu.Lock()
+	memes := append([]imgflipgo.Meme(nil), s.memes...)
+	modified := s.modified
+	s.mu.Unlock()
+
+	encoded, _ := json.Marshal(memes)
+	hash := fnv.New64a()
+	hash.Write(encoded)
+	etag := fmt.Sprintf(`"%x"`, hash.Sum64())
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if match == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeData(w, map[string]interface{}{"memes": memes})
+}
+
+func (s *Server) handleGetMeme(w http.ResponseWriter, r *http.Request) {
+	req, _ := s.record("get_meme", r)
+	if !s.authorized(req.Form) {
+		writeError(w, ErrMsgInvalidCredentials)
+		return
+	}
+	meme, ok := s.findMeme(req.Form.Get("template_id"))
+	if !ok {
+		writeError(w, ErrMsgTemplateNotFound)
+		return
+	}
+	writeData(w, map[string]interface{}{"meme": meme})
+}
+
+func (s *Server) handleSearchMemes(w http.ResponseWriter, r *http.Request) {
+	req, _ := s.record("search_memes", r)
+	if !s.authorized(req.Form) {
+		writeError(w, ErrMsgInvalidCredentials)
+		return
+	}
+	query := strings.ToLower(strings.TrimSpace(req.Form.Get("query")))
+	if query == "" {
+		writeError(w, ErrMsgNoQuery)
+		return
+	}
+
+	matches := []imgflipgo.Meme{}
+	s.mu.Lock()
+	for _, meme := range s.memes {
+		if strings.Contains(strings.ToLower(meme.Name), query) {
+			matches = append(matches, meme)
+		}
+	}
+	s.mu.Unlock()
+
+	writeData(w, map[string]interface{}{"memes": matches})
+}
+
+func (s *Server) handleCaptionImage(w http.ResponseWriter, r *http.Request) {
+	req, errMsg := s.record("caption_image", r)
+	if errMsg != "" {
+		writeError(w, errMsg)
+		return
+	}
+	if !s.authorized(req.Form) {
+		writeError(w, ErrMsgInvalidCredentials)
+		return
+	}
+	if _, ok := s.findMeme(req.Form.Get("template_id")); !ok {
+		writeError(w, ErrMsgTemplateNotFound)
+		return
+	}
+	if req.Form.Get("text0") == "" && req.Form.Get("text1") == "" && !hasText(req.TextBoxes) {
+		writeError(w, ErrMsgNoTexts)
+		return
+	}
+	writeData(w, s.newCaption("jpg"))
+}
+
+func (s *Server) handleCaptionGif(w http.ResponseWriter, r *http.Request) {
+	req, errMsg := s.record("caption_gif", r)
+	if errMsg != "" {
+		writeError(w, errMsg)
+		return
+	}
+	if !s.authorized(req.Form) {
+		writeError(w, ErrMsgInvalidCredentials)
+		return
+	}
+	if _, ok := s.findMeme(req.Form.Get("template_id")); !ok {
+		writeError(w, ErrMsgTemplateNotFound)
+		return
+	}
+	if !hasText(req.TextBoxes) {
+		writeError(w, ErrMsgNoTexts)
+		return
+	}
+	writeData(w, s.newCaption("gif"))
+}
+
+func (s *Server) handleAutoMeme(w http.ResponseWriter, r *http.Request) {
+	req, _ := s.record("automeme", r)
+	if !s.authorized(req.Form) {
+		writeError(w, ErrMsgInvalidCredentials)
+		return
+	}
+	if strings.TrimSpace(req.Form.Get("text")) == "" {
+		writeError(w, ErrMsgNoText)
+		return
+	}
+	writeData(w, s.newCaption("jpg"))
+}
+
+func (s *Server) handleAIMeme(w http.ResponseWriter, r *http.Request) {
+	req, _ := s.record("ai_meme", r)
+	if !s.authorized(req.Form) {
+		writeError(w, ErrMsgInvalidCredentials)
+		return
+	}
+
+	templateID := req.Form.Get("template_id")
+	if templateID == "" {
+		s.mu.Lock()
+		if len(s.memes) > 0 {
+			templateID = s.memes[0].ID
+		}
+		s.mu.Unlock()
+	}
+	meme, ok := s.findMeme(templateID)
+	if !ok {
+		writeError(w, ErrMsgTemplateNotFound)
+		return
+	}
+
+	data := s.newCaption("jpg")
+	data.TemplateID = meme.ID
+	data.Texts = make([]string, meme.BoxCount)
+	for i := range data.Texts {
+		data.Texts[i] = fmt.Sprintf("generated text %d", i+1)
+	}
+	if prefix := req.Form.Get("prefix_text"); prefix != "" && len(data.Texts) > 0 {
+		data.Texts[0] = prefix
+	}
+	writeData(w, data)
+}
+
+// ImageWidth and ImageHeight are the dimensions of the placeholder images the
+// Server serves for captions.
+const (
+	ImageWidth  = 64
+	ImageHeight = 48
+)
+
+// handleImage serves a placeholder image for a caption created by the Server.
+// Image requests are not recorded.
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/images/")
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	n, err := strconv.ParseInt(name[:dot], 36, 64)
+	s.mu.Lock()
+	created := err == nil && n > 0 && n <= int64(s.captions)
+	s.mu.Unlock()
+	if !created {
+		http.NotFound(w, r)
+		return
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, ImageWidth, ImageHeight), color.Palette{color.Gray{Y: 0x80}})
+	buf := bytes.Buffer{}
+	switch name[dot+1:] {
+	case "jpg":
+		w.Header().Set("Content-Type", "image/jpeg")
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		w.Header().Set("Content-Type", "image/gif")
+		err = gif.Encode(&buf, img, nil)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+// parseTextBoxes parses the boxes[i][field] entries of form with
+// imgflipgo.ParseCaptionForm, so that the Server accepts exactly the forms the
+// library can decode. If they are malformed, an error message is returned.
+func parseTextBoxes(form url.Values) ([]imgflipgo.TextBox, string) {
+	boxes := url.Values{}
+	for key, values := range form {
+		if strings.HasPrefix(key, "boxes") {
+			boxes[key] = values
+		}
+	}
+	req, err := imgflipgo.ParseCaptionForm(boxes)
+	if err != nil {
+		return nil, ErrMsgMalformedBoxes
+	}
+	if len(req.TextBoxes) > imgflipgo.MaxTextBoxes {
+		return nil, ErrMsgTooManyBoxes
+	}
+	return req.TextBoxes, ""
+}
+
+func hasText(textBoxes []imgflipgo.TextBox) bool {
+	for _, tb := range textBoxes {
+		if tb.Text != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package imgflipgotest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func TestGetMemes(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	memes, err := srv.NewClient().GetMemes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != len(imgflipgotest.Memes) {
+		t.Fatalf("expected %d memes, got %d", len(imgflipgotest.Memes), len(memes))
+	}
+
+	srv.SetMemes(imgflipgotest.Memes[:1])
+	memes, err = srv.NewClient().GetMemes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != 1 {
+		t.Fatalf("expected 1 meme after SetMemes, got %d", len(memes))
+	}
+}
+
+func TestCaptionImageRecordsTextBoxes(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	resp, err := srv.NewClient().CaptionImage(&imgflipgo.CaptionRequest{
+		TemplateID: imgflipgotest.Memes[0].ID,
+		TextBoxes: []imgflipgo.TextBox{
+			{},
+			*(&imgflipgo.TextBox{Text: "bottom"}).SetX(1).SetY(2).SetWidth(3).SetHeight(4).SetColor(0xFFA500).SetOutlineColor(0x0000FF),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.URL == "" || resp.Data.PageURL == "" {
+		t.Fatalf("expected URLs in response, got %+v", resp)
+	}
+
+	requests := srv.Requests()
+	if len(requests) != 1 || requests[0].Endpoint != "caption_image" {
+		t.Fatalf("unexpected requests %+v", requests)
+	}
+	boxes := requests[0].TextBoxes
+	if len(boxes) != 2 {
+		t.Fatalf("expected 2 text boxes, got %d", len(boxes))
+	}
+	got := boxes[1]
+	if got.Text != "bottom" || *got.X != 1 || *got.Y != 2 || *got.Width != 3 || *got.Height != 4 ||
+		*got.Color != 0xFFA500 || *got.OutlineColor != 0x0000FF {
+		t.Fatalf("text box was not parsed correctly: %+v", got)
+	}
+}
+
+func TestCaptionImageRejectsMalformedBoxes(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	username, password := srv.Credentials()
+
+	tests := []struct {
+		boxes string
+		want  string
+	}{
+		{"boxes[0][text]=a&boxes[2][text]=b", imgflipgotest.ErrMsgMalformedBoxes},
+		{"boxes[0][color]=orange", imgflipgotest.ErrMsgMalformedBoxes},
+		{"boxes[0][size]=1", imgflipgotest.ErrMsgMalformedBoxes},
+		{"boxes[0]=a", imgflipgotest.ErrMsgMalformedBoxes},
+		{strings.Repeat("&boxes[0][text]=a", 2)[1:], imgflipgotest.ErrMsgMalformedBoxes},
+		{tooManyBoxes(), imgflipgotest.ErrMsgTooManyBoxes},
+	}
+	for _, test := range tests {
+		form := url.Values{"template_id": {imgflipgotest.Memes[0].ID}, "username": {username}, "password": {password}}.Encode()
+		resp, err := http.Post(srv.URL+"/caption_image", "application/x-www-form-urlencoded", strings.NewReader(form+"&"+test.boxes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := imgflipgo.CaptionResponse{}
+		err = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Success || out.ErrorMsg != test.want {
+			t.Errorf("%s: expected %q, got %+v", test.boxes, test.want, out)
+		}
+	}
+}
+
+func tooManyBoxes() string {
+	form := url.Values{}
+	for i := 0; i <= imgflipgo.MaxTextBoxes; i++ {
+		form.Set(fmt.Sprintf("boxes[%d][text]", i), "a")
+	}
+	return form.Encode()
+}
+
+func TestErrors(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	req := (&imgflipgo.CaptionRequest{TemplateID: "0"}).SetTopText("top")
+	_, err := srv.NewClient().CaptionImage(req)
+	if !errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+
+	req.TemplateID = imgflipgotest.Memes[0].ID
+	_, err = srv.NewClient(imgflipgo.WithCredentials("someone", "else")).CaptionImage(req)
+	if !errors.Is(err, imgflipgo.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+
+	_, err = srv.NewClient().GetMeme("0")
+	if !errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestAIMeme(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	resp, err := srv.NewClient().AIMeme(&imgflipgo.AIMemeRequest{PrefixText: "when the tests"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.TemplateID == "" || len(resp.Data.Texts) == 0 || resp.Data.Texts[0] != "when the tests" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
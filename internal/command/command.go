@@ -0,0 +1,133 @@
+// Package command implements the parts of the slack and discord slash commands
+// that don't depend on the chat service: parsing a command's template and
+// text, captioning the template found in a MemeCatalog, and deferring captions
+// that take too long to respond with.
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+// DefaultDeferAfter is how long a Captioner waits for a caption before
+// deferring it. Slack and Discord both require a response within three
+// seconds.
+const DefaultDeferAfter = 2 * time.Second
+
+// DefaultTimeout bounds how long captioning a command may take, including
+// deferred commands.
+const DefaultTimeout = time.Minute
+
+// MaxBodySize is the largest request body a handler reads.
+const MaxBodySize = 1 << 20
+
+// Errors returned by Parse.
+var (
+	ErrNoTemplate = errors.New("no template specified")
+	ErrNoText     = errors.New("no text specified")
+)
+
+// Parse trims query, and splits "text | more text" into the text of each box.
+// At least one box must have text.
+func Parse(query, text string) (string, []string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", nil, ErrNoTemplate
+	}
+	texts := strings.Split(text, "|")
+	for i := range texts {
+		texts[i] = strings.TrimSpace(texts[i])
+	}
+	if len(texts) > imgflipgo.MaxTextBoxes {
+		return "", nil, fmt.Errorf("at most %d text boxes are allowed", imgflipgo.MaxTextBoxes)
+	}
+	for _, t := range texts {
+		if t != "" {
+			return query, texts, nil
+		}
+	}
+	return "", nil, ErrNoText
+}
+
+// Result is the outcome of captioning a command. If Err is set, its message is
+// a sentence to show the user who sent the command.
+type Result struct {
+	Meme     imgflipgo.Meme
+	Response imgflipgo.CaptionResponse
+	Err      error
+}
+
+// Captioner captions the templates named by commands. Its fields must not be
+// changed once it's in use.
+type Captioner struct {
+	Client     *imgflipgo.Client
+	Catalog    *imgflipgo.MemeCatalog
+	DeferAfter time.Duration
+	Timeout    time.Duration
+
+	// pending tracks deferred captions that haven't been followed up yet.
+	pending sync.WaitGroup
+}
+
+// Caption captions the template matching query with texts.
+func (c *Captioner) Caption(ctx context.Context, query string, texts []string) Result {
+	meme, err := c.Catalog.Find(ctx, query)
+	if errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+		return Result{Err: fmt.Errorf("No template matches %q.", query)}
+	}
+	if err != nil {
+		return Result{Err: fmt.Errorf("Couldn't look up templates: %w", err)}
+	}
+
+	req := &imgflipgo.CaptionRequest{TemplateID: meme.ID}
+	for _, text := range texts {
+		req.TextBoxes = append(req.TextBoxes, imgflipgo.TextBox{Text: text})
+	}
+	resp, err := c.Client.CaptionImageContext(ctx, req)
+	if err != nil {
+		return Result{Meme: meme, Err: fmt.Errorf("Couldn't caption %s: %w", meme.Name, err)}
+	}
+	return Result{Meme: meme, Response: resp}
+}
+
+// Start captions the template matching query with texts in the background,
+// bounded by Timeout. If the caption is done within DeferAfter, its result is
+// returned with ok set. Otherwise, followup is called with the result once it's
+// done, and the caller should acknowledge the command in the meantime. If
+// DeferAfter is not positive, every caption is deferred.
+func (c *Captioner) Start(query string, texts []string, followup func(Result)) (result Result, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	done := make(chan Result, 1)
+	go func() {
+		defer cancel()
+		done <- c.Caption(ctx, query, texts)
+	}()
+
+	if c.DeferAfter > 0 {
+		timer := time.NewTimer(c.DeferAfter)
+		defer timer.Stop()
+		select {
+		case result := <-done:
+			return result, true
+		case <-timer.C:
+		}
+	}
+
+	c.pending.Add(1)
+	go func() {
+		defer c.pending.Done()
+		followup(<-done)
+	}()
+	return Result{}, false
+}
+
+// Wait blocks until every deferred caption has been followed up.
+func (c *Captioner) Wait() {
+	c.pending.Wait()
+}
@@ -0,0 +1,74 @@
+package command_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+	"github.com/Kardbord/imgflipgo/v2/internal/command"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		texts []string
+		err   error
+	}{
+		{" drake ", "no tests | tests in prod", []string{"no tests", "tests in prod"}, nil},
+		{"drake", "| a |", []string{"", "a", ""}, nil},
+		{" ", "a", nil, command.ErrNoTemplate},
+		{"drake", "", nil, command.ErrNoText},
+		{"drake", " | ", nil, command.ErrNoText},
+	}
+	for _, test := range tests {
+		query, texts, err := command.Parse(test.query, test.text)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%q, %q: expected %v, got %v", test.query, test.text, test.err, err)
+		}
+		if err == nil && (query != strings.TrimSpace(test.query) || !reflect.DeepEqual(texts, test.texts)) {
+			t.Errorf("%q, %q: unexpected %q, %q", test.query, test.text, query, texts)
+		}
+	}
+	if _, _, err := command.Parse("drake", strings.Repeat("a|", imgflipgo.MaxTextBoxes)); err == nil {
+		t.Error("expected too many text boxes to be rejected")
+	}
+}
+
+func TestCaptionerStart(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	client := api.NewClient()
+	c := &command.Captioner{
+		Client:     client,
+		Catalog:    imgflipgo.NewMemeCatalog(client, time.Hour),
+		DeferAfter: time.Minute,
+		Timeout:    time.Minute,
+	}
+
+	result, ok := c.Start("drake", []string{"a", "b"}, func(command.Result) {
+		t.Error("expected the caption not to be deferred")
+	})
+	if !ok || result.Err != nil || result.Meme.Name != "Drake Hotline Bling" || result.Response.Data.URL == "" {
+		t.Errorf("unexpected result %+v, %v", result, ok)
+	}
+
+	c.DeferAfter = 0
+	deferred := make(chan command.Result, 1)
+	if _, ok := c.Start("zzzzzz", []string{"a"}, func(result command.Result) { deferred <- result }); ok {
+		t.Error("expected the caption to be deferred")
+	}
+	c.Wait()
+	select {
+	case result := <-deferred:
+		if result.Err == nil || !strings.Contains(result.Err.Error(), `No template matches "zzzzzz"`) {
+			t.Errorf("expected a missing template, got %v", result.Err)
+		}
+	default:
+		t.Fatal("expected the deferred caption to be followed up")
+	}
+}
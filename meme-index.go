@@ -0,0 +1,268 @@
+package imgflipgo
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// abbreviations maps common shorthand in template searches to the word it
+// stands for. Queries, names, and aliases are expanded with it before matching.
+var abbreviations = map[string]string{
+	"bf":  "boyfriend",
+	"gf":  "girlfriend",
+	"ppl": "people",
+	"pls": "please",
+	"plz": "please",
+	"vs":  "versus",
+}
+
+// minMatchScore is the lowest score that Search will return.
+const minMatchScore = 0.3
+
+// MemeMatch is a Meme matched by MemeIndex.Search.
+type MemeMatch struct {
+	Meme Meme
+
+	// Score is how well the Meme matched, from 0 (not at all) to 1 (exactly).
+	Score float64
+}
+
+// MemeIndex is an in-memory fuzzy search index over a set of templates, e.g.
+// the output of GetMemes or MemeCatalog.Memes. It matches queries against
+// template names and aliases by token, with tolerance for typos, and by
+// trigram similarity. A MemeIndex is safe for concurrent use.
+type MemeIndex struct {
+	mu      sync.RWMutex
+	entries []indexEntry
+	byID    map[string]int
+}
+
+type indexEntry struct {
+	meme  Meme
+	names []indexedName
+}
+
+type indexedName struct {
+	normalized string
+	tokens     []string
+	trigrams   map[string]struct{}
+}
+
+// NewMemeIndex creates a MemeIndex over memes. The order of memes is used to
+// break ties between equally good matches, so more popular templates, which
+// get_memes lists first, are preferred.
+func NewMemeIndex(memes []Meme) *MemeIndex {
+	idx := &MemeIndex{
+		entries: make([]indexEntry, 0, len(memes)),
+		byID:    make(map[string]int, len(memes)),
+	}
+	for _, meme := range memes {
+		if _, ok := idx.byID[meme.ID]; ok {
+			continue
+		}
+		idx.byID[meme.ID] = len(idx.entries)
+		idx.entries = append(idx.entries, indexEntry{
+			meme:  meme,
+			names: []indexedName{newIndexedName(meme.Name)},
+		})
+	}
+	return idx
+}
+
+// AddAlias makes the template with the given ID also match alias, e.g.
+// "galaxy brain" for Expanding Brain. It reports whether the template is in
+// the index.
+func (idx *MemeIndex) AddAlias(templateID string, alias string) bool {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	i, ok := idx.byID[templateID]
+	if !ok {
+		return false
+	}
+	idx.entries[i].names = append(idx.entries[i].names, newIndexedName(alias))
+	return true
+}
+
+// Search returns up to limit templates matching query, best first. If limit is
+// not positive, every match is returned.
+func (idx *MemeIndex) Search(query string, limit int) []MemeMatch {
+	q := newIndexedName(query)
+	if q.normalized == "" {
+		return nil
+	}
+
+	idx.mu.RLock()
+	type scored struct {
+		order int
+		match MemeMatch
+	}
+	var results []scored
+	for i, entry := range idx.entries {
+		best := 0.0
+		for _, name := range entry.names {
+			if score := similarity(q, name); score > best {
+				best = score
+			}
+		}
+		if best >= minMatchScore {
+			results = append(results, scored{order: i, match: MemeMatch{Meme: entry.meme, Score: best}})
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].match.Score != results[j].match.Score {
+			return results[i].match.Score > results[j].match.Score
+		}
+		return results[i].order < results[j].order
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	matches := make([]MemeMatch, len(results))
+	for i := range results {
+		matches[i] = results[i].match
+	}
+	return matches
+}
+
+// Best returns the best match for query, if there is one.
+func (idx *MemeIndex) Best(query string) (MemeMatch, bool) {
+	matches := idx.Search(query, 1)
+	if len(matches) == 0 {
+		return MemeMatch{}, false
+	}
+	return matches[0], true
+}
+
+func newIndexedName(name string) indexedName {
+	tokens := tokenize(name)
+	normalized := strings.Join(tokens, " ")
+	return indexedName{
+		normalized: normalized,
+		tokens:     tokens,
+		trigrams:   trigrams(normalized),
+	}
+}
+
+// tokenize lowercases s, splits it into words on anything that isn't a letter
+// or digit, and expands abbreviations. Apostrophes are dropped rather than
+// split on, so "Gru's" becomes "grus".
+func tokenize(s string) []string {
+	s = strings.ReplaceAll(strings.ToLower(s), "'", "")
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		if expanded, ok := abbreviations[word]; ok {
+			words[i] = expanded
+		}
+	}
+	return words
+}
+
+func trigrams(s string) map[string]struct{} {
+	padded := []rune("  " + s + " ")
+	grams := make(map[string]struct{}, len(padded))
+	for i := 0; i+3 <= len(padded); i++ {
+		grams[string(padded[i:i+3])] = struct{}{}
+	}
+	return grams
+}
+
+// similarity scores how well query matches name, from 0 to 1.
+func similarity(query, name indexedName) float64 {
+	if query.normalized == name.normalized {
+		return 1
+	}
+
+	// Dice coefficient of the two trigram sets.
+	shared := 0
+	for gram := range query.trigrams {
+		if _, ok := name.trigrams[gram]; ok {
+			shared++
+		}
+	}
+	trigramScore := 2 * float64(shared) / float64(len(query.trigrams)+len(name.trigrams))
+
+	// Average of how well each query token matches its best name token.
+	tokenScore := 0.0
+	for _, qt := range query.tokens {
+		best := 0.0
+		for _, nt := range name.tokens {
+			if score := tokenSimilarity(qt, nt); score > best {
+				best = score
+			}
+		}
+		tokenScore += best
+	}
+	tokenScore /= float64(len(query.tokens))
+
+	score := 0.65*tokenScore + 0.35*trigramScore
+	if score > 0.99 {
+		score = 0.99
+	}
+	return score
+}
+
+// tokenSimilarity scores how well a query token matches a name token,
+// tolerating prefixes and small typos.
+func tokenSimilarity(query, name string) float64 {
+	switch {
+	case query == name:
+		return 1
+	case len(query) >= 2 && strings.HasPrefix(name, query):
+		return 0.9
+	}
+
+	distance := editDistance(query, name)
+	switch {
+	case len(query) >= 4 && distance == 1:
+		return 0.8
+	case len(query) >= 7 && distance == 2:
+		return 0.6
+	}
+	return 0
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions, and transpositions of
+// adjacent characters needed to turn one into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d := minInt(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d = minInt(d, rows[i-2][j-2]+1)
+			}
+			rows[i][j] = d
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
@@ -0,0 +1,77 @@
+package imgflipgo_test
+
+import (
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func TestMemeIndexSearch(t *testing.T) {
+	idx := imgflipgo.NewMemeIndex(imgflipgotest.Memes)
+
+	tests := []struct {
+		query string
+		name  string
+	}{
+		{"drake", "Drake Hotline Bling"},
+		{"Drake Hotline Bling", "Drake Hotline Bling"},
+		{"distracted bf", "Distracted Boyfriend"},
+		{"drkae", "Drake Hotline Bling"},
+		{"yelling cat", "Woman Yelling At Cat"},
+		{"uno", "UNO Draw 25 Cards"},
+		{"change my mnd", "Change My Mind"},
+		{"spongebob", "Mocking Spongebob"},
+		{"batman slap", "Batman Slapping Robin"},
+	}
+	for _, test := range tests {
+		match, ok := idx.Best(test.query)
+		if !ok {
+			t.Errorf("%q: expected a match", test.query)
+			continue
+		}
+		if match.Meme.Name != test.name {
+			t.Errorf("%q: expected %q, got %q (score %.2f)", test.query, test.name, match.Meme.Name, match.Score)
+		}
+	}
+}
+
+func TestMemeIndexScores(t *testing.T) {
+	idx := imgflipgo.NewMemeIndex(imgflipgotest.Memes)
+
+	matches := idx.Search("success kid", 0)
+	if len(matches) == 0 || matches[0].Score != 1 {
+		t.Fatalf("expected an exact match to score 1, got %+v", matches)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Fatal("expected matches to be ranked by score")
+		}
+	}
+
+	if matches := idx.Search("zzzzqqq", 0); len(matches) != 0 {
+		t.Fatalf("expected no matches for nonsense, got %+v", matches)
+	}
+	if matches := idx.Search("", 0); len(matches) != 0 {
+		t.Fatalf("expected no matches for an empty query, got %+v", matches)
+	}
+	if matches := idx.Search("a", 2); len(matches) > 2 {
+		t.Fatalf("expected at most 2 matches, got %d", len(matches))
+	}
+}
+
+func TestMemeIndexAlias(t *testing.T) {
+	idx := imgflipgo.NewMemeIndex(imgflipgotest.Memes)
+
+	if !idx.AddAlias("93895088", "galaxy brain") {
+		t.Fatal("expected alias to be added")
+	}
+	if idx.AddAlias("0", "nothing") {
+		t.Fatal("did not expect an alias to be added for an unknown template")
+	}
+
+	match, ok := idx.Best("galaxy brain")
+	if !ok || match.Meme.ID != "93895088" || match.Score != 1 {
+		t.Fatalf("expected alias to match exactly, got %+v", match)
+	}
+}
@@ -0,0 +1,136 @@
+package memespec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+// Result is the outcome of captioning one Entry.
+type Result struct {
+	// Index of the entry in Spec.Memes.
+	Index int
+	Entry Entry
+
+	// Response from the captioning request.
+	Response imgflipgo.CaptionResponse
+
+	// Output is the file the image was written to, if the entry has one.
+	Output string
+
+	// Err is the first error encountered captioning or downloading the entry.
+	Err error
+}
+
+// Run captions every entry in spec with client, or imgflipgo.DefaultClient if
+// client is nil, and downloads each captioned image to the entry's output, if
+// any. Requests are sent with Client.CaptionBatch, configured by opts.
+//
+// Template names are resolved with a single get_memes request before anything
+// is captioned; if that fails, or a name doesn't match a template, Run returns
+// an error and nothing is captioned. Otherwise, a Result is returned for every
+// entry, in order, and failed entries don't stop the rest.
+func Run(ctx context.Context, client *imgflipgo.Client, spec *Spec, opts ...imgflipgo.BatchOption) ([]Result, error) {
+	if client == nil {
+		client = imgflipgo.DefaultClient
+	}
+	err := spec.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	templateIDs, err := resolveTemplates(ctx, client, spec.Memes)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := make([]imgflipgo.CaptionRequest, len(spec.Memes))
+	for i, e := range spec.Memes {
+		reqs[i] = *spec.Request(e, templateIDs[i])
+	}
+
+	batch := client.CaptionBatch(ctx, reqs, opts...)
+	results := make([]Result, len(batch))
+	for i, b := range batch {
+		e := spec.Memes[i]
+		results[i] = Result{Index: i, Entry: e, Response: b.Response, Err: b.Err}
+		if b.Err != nil || e.Output == "" {
+			continue
+		}
+		output := spec.OutputPath(e)
+		err := download(ctx, client, b.Response.Data.URL, output)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Output = output
+	}
+	return results, nil
+}
+
+// resolveTemplates returns the template ID of each entry, looking up template
+// names with get_memes if any entries use them.
+func resolveTemplates(ctx context.Context, client *imgflipgo.Client, entries []Entry) ([]string, error) {
+	ids := make([]string, len(entries))
+	var byName map[string]string
+	var problems []error
+	for i, e := range entries {
+		if e.TemplateID != "" {
+			ids[i] = e.TemplateID
+			continue
+		}
+
+		if byName == nil {
+			memes, err := client.GetMemesContext(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("resolving template names: %w", err)
+			}
+			// Memes are ordered by popularity, so the most popular template
+			// wins if names collide.
+			byName = map[string]string{}
+			for j := len(memes) - 1; j >= 0; j-- {
+				byName[normalizeName(memes[j].Name)] = memes[j].ID
+			}
+		}
+		id, ok := byName[normalizeName(e.TemplateName)]
+		if !ok {
+			problems = append(problems, fmt.Errorf("%s: %w: %q", e.Label(i), imgflipgo.ErrTemplateNotFound, e.TemplateName))
+			continue
+		}
+		ids[i] = id
+	}
+
+	if len(problems) > 0 {
+		return nil, &imgflipgo.ValidationError{Problems: problems}
+	}
+	return ids, nil
+}
+
+// normalizeName lowercases name and drops everything but letters and digits.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+func download(ctx context.Context, client *imgflipgo.Client, imageURL, output string) error {
+	data, err := client.DownloadImage(ctx, imageURL)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(output); dir != "." {
+		err = os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(output, data, 0o644)
+}
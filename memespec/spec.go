@@ -0,0 +1,238 @@
+// Package memespec defines a file format for declaring memes, so that they can
+// be kept in version control and captioned in bulk.
+//
+// A spec file is YAML (or JSON, which is valid YAML) of the form:
+//
+//	defaults:                    # optional, applied to every meme
+//	  font: impact               # impact or arial
+//	  max_font_size: 40          # in pixels
+//	  output_dir: out            # directory that outputs are written to
+//	memes:
+//	  - name: drake-docs         # optional, used in messages
+//	    template_id: "181913649" # either template_id...
+//	    top_text: writing docs   # top_text/bottom_text, or boxes
+//	    bottom_text: writing code
+//	    output: drake.jpg        # optional, file to download the image to
+//	  - template_name: Distracted Boyfriend # ...or template_name
+//	    font: arial
+//	    boxes:
+//	      - text: new framework
+//	        color: "#FFA500"
+//	        outline_color: "#000000"
+//	      - text: me
+//	      - text: the framework I know
+//	        x: 10
+//	        y: 10
+//	        width: 200
+//	        height: 100
+//
+// Template names are matched against the names returned by get_memes exactly,
+// ignoring case, spaces, and punctuation, so "distracted-boyfriend" matches
+// "Distracted Boyfriend" but "distracted bf" doesn't. Unknown fields are
+// rejected.
+package memespec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the contents of a spec file.
+type Spec struct {
+	Defaults Defaults `yaml:"defaults" json:"defaults"`
+	Memes    []Entry  `yaml:"memes" json:"memes"`
+}
+
+// Defaults apply to every Entry that doesn't set the same field.
+type Defaults struct {
+	Font        string `yaml:"font" json:"font,omitempty"`
+	MaxFontSize *uint  `yaml:"max_font_size" json:"max_font_size,omitempty"`
+
+	// OutputDir is the directory that each Entry's Output is relative to.
+	OutputDir string `yaml:"output_dir" json:"output_dir,omitempty"`
+}
+
+// Entry declares a single meme. It maps onto an imgflipgo.CaptionRequest.
+type Entry struct {
+	// [optional] Name identifies the entry in results and error messages.
+	Name string `yaml:"name" json:"name,omitempty"`
+
+	// Exactly one of TemplateID and TemplateName is required.
+	TemplateID   string `yaml:"template_id" json:"template_id,omitempty"`
+	TemplateName string `yaml:"template_name" json:"template_name,omitempty"`
+
+	// Either TopText and BottomText, or Boxes, may be specified.
+	TopText    *string `yaml:"top_text" json:"top_text,omitempty"`
+	BottomText *string `yaml:"bottom_text" json:"bottom_text,omitempty"`
+	Boxes      []Box   `yaml:"boxes" json:"boxes,omitempty"`
+
+	// [optional] Font is "impact" or "arial".
+	Font        string `yaml:"font" json:"font,omitempty"`
+	MaxFontSize *uint  `yaml:"max_font_size" json:"max_font_size,omitempty"`
+
+	// [optional] Output is a file to download the captioned image to.
+	Output string `yaml:"output" json:"output,omitempty"`
+}
+
+// Box maps onto an imgflipgo.TextBox. Colors are strings understood by
+// imgflipgo.ParseColor, such as "#FFA500" or "orange".
+type Box struct {
+	Text         string           `yaml:"text" json:"text"`
+	X            *uint            `yaml:"x" json:"x,omitempty"`
+	Y            *uint            `yaml:"y" json:"y,omitempty"`
+	Width        *uint            `yaml:"width" json:"width,omitempty"`
+	Height       *uint            `yaml:"height" json:"height,omitempty"`
+	Color        *imgflipgo.Color `yaml:"color" json:"color,omitempty"`
+	OutlineColor *imgflipgo.Color `yaml:"outline_color" json:"outline_color,omitempty"`
+}
+
+// Load reads and validates the spec file at path.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse decodes and validates a spec from YAML or JSON.
+func Parse(data []byte) (*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	spec := &Spec{}
+	err := dec.Decode(spec)
+	if err != nil {
+		return nil, err
+	}
+	err = spec.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Label is how the entry at index i is referred to in messages.
+func (e Entry) Label(i int) string {
+	if e.Name != "" {
+		return fmt.Sprintf("meme %d (%s)", i, e.Name)
+	}
+	return fmt.Sprintf("meme %d", i)
+}
+
+// Validate checks the spec for mistakes that can be found without contacting
+// the API. Every problem found is returned in an *imgflipgo.ValidationError.
+func (s *Spec) Validate() error {
+	var problems []error
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if !validFont(s.Defaults.Font) {
+		addf("defaults: unknown font %q", s.Defaults.Font)
+	}
+	if len(s.Memes) == 0 {
+		addf("no memes specified")
+	}
+
+	outputs := map[string]string{}
+	for i, e := range s.Memes {
+		label := e.Label(i)
+		if (e.TemplateID == "") == (e.TemplateName == "") {
+			addf("%s: exactly one of template_id and template_name is required", label)
+		}
+		if !validFont(e.Font) {
+			addf("%s: unknown font %q", label, e.Font)
+		}
+
+		// Check the request the entry maps onto with the same rules as
+		// CaptionImage. The template and credentials aren't known yet, so
+		// placeholders stand in for them.
+		req := s.Request(e, "-")
+		req.Username, req.Password = "-", "-"
+		var reqErr *imgflipgo.ValidationError
+		if errors.As(req.Validate(), &reqErr) {
+			for _, problem := range reqErr.Problems {
+				addf("%s: %w", label, problem)
+			}
+		}
+
+		if e.Output != "" {
+			output := s.OutputPath(e)
+			if other, ok := outputs[output]; ok {
+				addf("%s: output %s is also used by %s", label, output, other)
+			}
+			outputs[output] = label
+		}
+	}
+
+	if len(problems) > 0 {
+		return &imgflipgo.ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// OutputPath returns where e's image is written, taking Defaults.OutputDir into
+// account, or "" if e has no Output.
+func (s *Spec) OutputPath(e Entry) string {
+	if e.Output == "" {
+		return ""
+	}
+	if s.Defaults.OutputDir == "" || filepath.IsAbs(e.Output) {
+		return filepath.Clean(e.Output)
+	}
+	return filepath.Join(s.Defaults.OutputDir, e.Output)
+}
+
+// Request converts e into a CaptionRequest for the given template, applying the
+// spec's defaults. Credentials are left for the Client to fill in.
+func (s *Spec) Request(e Entry, templateID string) *imgflipgo.CaptionRequest {
+	req := &imgflipgo.CaptionRequest{
+		TemplateID: templateID,
+		TopText:    e.TopText,
+		BottomText: e.BottomText,
+	}
+
+	font := e.Font
+	if font == "" {
+		font = s.Defaults.Font
+	}
+	if font != "" {
+		req.SetFont(imgflipgo.Font(font))
+	}
+	maxFontSize := e.MaxFontSize
+	if maxFontSize == nil {
+		maxFontSize = s.Defaults.MaxFontSize
+	}
+	req.MaxFontSizePx = maxFontSize
+
+	for _, box := range e.Boxes {
+		req.TextBoxes = append(req.TextBoxes, imgflipgo.TextBox{
+			Text:         box.Text,
+			X:            box.X,
+			Y:            box.Y,
+			Width:        box.Width,
+			Height:       box.Height,
+			Color:        box.Color,
+			OutlineColor: box.OutlineColor,
+		})
+	}
+	return req
+}
+
+func validFont(font string) bool {
+	switch imgflipgo.Font(font) {
+	case "", imgflipgo.FontImpact, imgflipgo.FontArial:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package memespec_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+	"github.com/Kardbord/imgflipgo/v2/memespec"
+)
+
+const yamlSpec = `
+defaults:
+  font: arial
+  max_font_size: 40
+memes:
+  - name: drake
+    template_id: "181913649"
+    top_text: writing docs
+    bottom_text: writing code
+    output: drake.jpg
+  - template_name: DISTRACTED-boyfriend
+    font: impact
+    boxes:
+      - text: new framework
+        color: "#FFA500"
+        outline_color: black
+      - text: me
+      - text: the framework I know
+        x: 10
+        y: 20
+        width: 200
+        height: 100
+`
+
+const jsonSpec = `{
+  "memes": [
+    {"template_id": "181913649", "boxes": [{"text": "a", "color": "#ffffff"}, {"text": "b"}]}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	spec, err := memespec.Parse([]byte(yamlSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Memes) != 2 {
+		t.Fatalf("expected 2 memes, got %d", len(spec.Memes))
+	}
+
+	req := spec.Request(spec.Memes[0], spec.Memes[0].TemplateID)
+	if *req.TopText != "writing docs" || *req.BottomText != "writing code" {
+		t.Errorf("unexpected text %q, %q", *req.TopText, *req.BottomText)
+	}
+	if req.Font == nil || *req.Font != imgflipgo.FontArial {
+		t.Errorf("expected the default font to be applied, got %v", req.Font)
+	}
+	if req.MaxFontSizePx == nil || *req.MaxFontSizePx != 40 {
+		t.Errorf("expected the default max font size to be applied, got %v", req.MaxFontSizePx)
+	}
+
+	req = spec.Request(spec.Memes[1], "112126428")
+	if *req.Font != imgflipgo.FontImpact {
+		t.Errorf("expected the entry's font to override the default, got %s", *req.Font)
+	}
+	if len(req.TextBoxes) != 3 {
+		t.Fatalf("expected 3 text boxes, got %d", len(req.TextBoxes))
+	}
+	if tb := req.TextBoxes[0]; *tb.Color != 0xFFA500 || *tb.OutlineColor != 0 {
+		t.Errorf("unexpected colors %06x, %06x", *tb.Color, *tb.OutlineColor)
+	}
+	if tb := req.TextBoxes[2]; *tb.X != 10 || *tb.Y != 20 || *tb.Width != 200 || *tb.Height != 100 {
+		t.Errorf("unexpected geometry %+v", tb)
+	}
+
+	spec, err = memespec.Parse([]byte(jsonSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Memes) != 1 || len(spec.Memes[0].Boxes) != 2 {
+		t.Errorf("unexpected spec %+v", spec)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		problem string
+	}{
+		{"unknown field", "memes:\n  - template_id: '1'\n    top_txt: a\n", "field top_txt not found"},
+		{"no memes", "defaults:\n  font: arial\n", "no memes specified"},
+		{"no template", "memes:\n  - top_text: a\n", "exactly one of template_id and template_name"},
+		{"both templates", "memes:\n  - {template_id: '1', template_name: a, top_text: a}\n", "exactly one of template_id and template_name"},
+		{"unknown font", "memes:\n  - {template_id: '1', top_text: a, font: comic}\n", `unknown font "comic"`},
+		{"no text", "memes:\n  - {template_id: '1'}\n", "no text specified"},
+		{"text and boxes", "memes:\n  - {template_id: '1', top_text: a, boxes: [{text: b}]}\n", "ignored when TextBoxes are specified"},
+		{"bad color", "memes:\n  - {template_id: '1', boxes: [{text: b, color: orangey}]}\n", `invalid color "orangey"`},
+		{"partial geometry", "memes:\n  - {template_id: '1', boxes: [{text: b, x: 1}]}\n", "must be specified together"},
+		{"duplicate output", "memes:\n  - {template_id: '1', top_text: a, output: a.jpg}\n  - {template_id: '1', top_text: b, output: ./a.jpg}\n", "also used by meme 0"},
+	}
+	for _, test := range tests {
+		_, err := memespec.Parse([]byte(test.spec))
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.problem) {
+			t.Errorf("%s: expected %q, got %v", test.name, test.problem, err)
+		}
+	}
+
+	_, err := memespec.Parse([]byte("memes:\n  - {template_id: '1'}\n  - {template_id: '2', font: x, top_text: a}\n"))
+	valErr := &imgflipgo.ValidationError{}
+	if !errors.As(err, &valErr) || len(valErr.Problems) != 2 {
+		t.Errorf("expected every problem to be reported, got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "memes.yaml")
+	spec := strings.Replace(yamlSpec, "max_font_size: 40", "max_font_size: 40\n  output_dir: "+dir, 1)
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := memespec.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := memespec.Run(context.Background(), srv.NewClient(), loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.Entry.Label(result.Index), result.Err)
+		}
+	}
+
+	output := filepath.Join(dir, "drake.jpg")
+	if results[0].Output != output {
+		t.Errorf("expected output %s, got %s", output, results[0].Output)
+	}
+	if info, err := os.Stat(output); err != nil || info.Size() == 0 {
+		t.Errorf("expected the image to be written to %s: %v", output, err)
+	}
+
+	// The template name should have been resolved to Distracted Boyfriend.
+	requests := srv.Requests()
+	found := false
+	for _, req := range requests {
+		if req.Endpoint == "caption_image" && req.Form.Get("template_id") == "112126428" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the template name to be resolved to its ID")
+	}
+}
+
+func TestRunUnknownTemplateName(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	// Names must match exactly, without abbreviations or fuzzy matching.
+	for _, name := range []string{"distracted bf girlfriend", "distracted bf", "distracted boyfriends"} {
+		spec, err := memespec.Parse([]byte("memes:\n  - {template_name: " + name + ", top_text: a}\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = memespec.Run(context.Background(), srv.NewClient(), spec)
+		if !errors.Is(err, imgflipgo.ErrTemplateNotFound) {
+			t.Errorf("%q: expected ErrTemplateNotFound, got %v", name, err)
+		}
+	}
+	for _, req := range srv.Requests() {
+		if req.Endpoint == "caption_image" {
+			t.Error("expected nothing to be captioned")
+		}
+	}
+}
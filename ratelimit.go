@@ -0,0 +1,168 @@
+package imgflipgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request is rejected by a fail-fast
+// RateLimiter, or when waiting for one would outlast the request's context.
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimiter is a token bucket that limits how often requests are sent. The
+// bucket holds up to burst tokens and refills at a steady rate; each request
+// takes one token. A RateLimiter is safe for concurrent use, and may be shared
+// by several Clients to enforce a combined limit.
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+	failFast bool
+}
+
+// NewRateLimiter creates a RateLimiter that allows one request per interval on
+// average, with bursts of up to burst requests. The bucket starts full. If
+// interval is not positive, requests are not limited at all, and a burst below
+// 1 is treated as 1.
+func NewRateLimiter(interval time.Duration, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// SetFailFast sets whether requests that would have to wait for a token fail
+// immediately with ErrRateLimited instead of blocking.
+func (l *RateLimiter) SetFailFast(failFast bool) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failFast = failFast
+	return l
+}
+
+// refill adds the tokens accumulated since the last refill. l.mu must be held.
+func (l *RateLimiter) refill(now time.Time) {
+	if l.interval <= 0 {
+		l.tokens = l.burst
+		l.last = now
+		return
+	}
+	elapsed := now.Sub(l.last)
+	if elapsed <= 0 {
+		return
+	}
+	l.tokens += float64(elapsed) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// Allow takes a token if one is available without waiting, and reports whether
+// it did.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until a token is available or ctx is done. If the RateLimiter is
+// fail-fast, or ctx's deadline would pass before a token becomes available,
+// ErrRateLimited is returned without waiting. If ctx is done first, ctx.Err()
+// is returned.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration((1 - l.tokens) * float64(l.interval))
+	if deadline, ok := ctx.Deadline(); l.failFast || (ok && deadline.Before(now.Add(delay))) {
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+	// Reserve the token now so that waiters are served in order.
+	l.tokens--
+	l.mu.Unlock()
+
+	err := sleepContext(ctx, delay)
+	if err != nil {
+		l.release()
+		return err
+	}
+	return nil
+}
+
+// release returns a token taken by Wait that ended up unused.
+func (l *RateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// WithRateLimiter limits every request made by the Client with limiter.
+func WithRateLimiter(limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// WithEndpointRateLimiter limits requests to a single endpoint, identified by
+// its name, e.g. "caption_image". It applies in addition to any limiter set
+// with WithRateLimiter.
+func WithEndpointRateLimiter(endpoint string, limiter *RateLimiter) ClientOption {
+	return func(c *Client) {
+		if c.endpointRateLimiters == nil {
+			c.endpointRateLimiters = map[string]*RateLimiter{}
+		}
+		c.endpointRateLimiters[endpoint] = limiter
+	}
+}
+
+// waitRateLimit waits for the Client's limiters to allow a request to endpoint.
+// The endpoint's limiter is checked first, since it's the more likely to fail
+// fast, and if any limiter fails, tokens already taken from the others are
+// returned.
+func (c *Client) waitRateLimit(ctx context.Context, endpoint string) error {
+	var taken []*RateLimiter
+	for _, limiter := range []*RateLimiter{c.endpointRateLimiters[endpoint], c.rateLimiter} {
+		if limiter == nil {
+			continue
+		}
+		err := limiter.Wait(ctx)
+		if err != nil {
+			for _, l := range taken {
+				l.release()
+			}
+		}
+		if errors.Is(err, ErrRateLimited) {
+			return fmt.Errorf("%s request: %w", endpoint, err)
+		}
+		if err != nil {
+			return contextError(ctx, endpoint, err)
+		}
+		taken = append(taken, limiter)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package imgflipgo_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := imgflipgo.NewRateLimiter(time.Hour, 2)
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("expected the initial burst to be allowed")
+	}
+	if limiter.Allow() {
+		t.Fatal("expected the bucket to be empty")
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		limiter := imgflipgo.NewRateLimiter(interval, 1).SetFailFast(true)
+		for i := 0; i < 10; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatalf("interval %v: expected no limit, got %v", interval, err)
+			}
+		}
+	}
+}
+
+func TestRateLimiterWaitConcurrent(t *testing.T) {
+	const interval = 10 * time.Millisecond
+	limiter := imgflipgo.NewRateLimiter(interval, 1)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 4*interval {
+		t.Fatalf("expected 5 requests to take at least %v, took %v", 4*interval, elapsed)
+	}
+}
+
+func TestRateLimiterWaitContext(t *testing.T) {
+	limiter := imgflipgo.NewRateLimiter(time.Hour, 1)
+	limiter.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, imgflipgo.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited when the deadline is too soon, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestClientRateLimitFailFast(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	client := srv.NewClient(imgflipgo.WithEndpointRateLimiter("get_memes", imgflipgo.NewRateLimiter(time.Hour, 1).SetFailFast(true)))
+	if _, err := client.GetMemes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMemes(); !errors.Is(err, imgflipgo.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	// Other endpoints are not affected by the get_memes limiter.
+	resp, err := client.CaptionImage((&imgflipgo.CaptionRequest{TemplateID: testTemplateID}).SetTopText("Top Text"))
+	expectSuccess(t, resp, err)
+
+	if n := len(srv.Requests()); n != 2 {
+		t.Fatalf("expected the rate limited request not to be sent, but the server saw %d requests", n)
+	}
+}
+
+func TestClientRateLimitRejectedKeepsTokens(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	// A request rejected by the endpoint limiter doesn't use up the global one.
+	global := imgflipgo.NewRateLimiter(time.Hour, 2).SetFailFast(true)
+	client := srv.NewClient(
+		imgflipgo.WithRateLimiter(global),
+		imgflipgo.WithEndpointRateLimiter("get_memes", imgflipgo.NewRateLimiter(time.Hour, 1).SetFailFast(true)),
+	)
+	if _, err := client.GetMemes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMemes(); !errors.Is(err, imgflipgo.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if !global.Allow() {
+		t.Error("expected the rejected request to leave the global token")
+	}
+
+	// A request rejected by the global limiter gives back the endpoint token.
+	endpoint := imgflipgo.NewRateLimiter(time.Hour, 2).SetFailFast(true)
+	client = srv.NewClient(
+		imgflipgo.WithRateLimiter(imgflipgo.NewRateLimiter(time.Hour, 1).SetFailFast(true)),
+		imgflipgo.WithEndpointRateLimiter("get_memes", endpoint),
+	)
+	if _, err := client.GetMemes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMemes(); !errors.Is(err, imgflipgo.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if !endpoint.Allow() {
+		t.Error("expected the endpoint token to be given back")
+	}
+}
@@ -0,0 +1,388 @@
+// Package render draws captions onto meme templates locally, without calling
+// the Imgflip API, following the same conventions imgflip uses.
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	// Register the gif decoder so that animated templates can be loaded. Only
+	// the first frame is rendered.
+	_ "image/gif"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// DefaultColor is the text color used when a TextBox doesn't specify one.
+	DefaultColor imgflipgo.Color = 0xFFFFFF
+
+	// DefaultOutlineColor is the outline color used when a TextBox doesn't
+	// specify one.
+	DefaultOutlineColor imgflipgo.Color = 0x000000
+
+	// minFontSizePx is the smallest size text is shrunk to when fitting it into
+	// its box.
+	minFontSizePx = 8
+)
+
+// Format is an output image format.
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatJPEG Format = "jpeg"
+)
+
+// Options configure a Renderer.
+type Options struct {
+	// [optional] Fonts maps each imgflipgo.Font to TrueType or OpenType font
+	// data. Impact and Arial can't be redistributed, so by default FontImpact is
+	// drawn with Go Bold and FontArial with Go Regular. Supply the real fonts
+	// here for output that more closely matches imgflip's.
+	Fonts map[imgflipgo.Font][]byte
+}
+
+// Renderer draws CaptionRequests onto template images. A Renderer is safe for
+// concurrent use.
+type Renderer struct {
+	fonts map[imgflipgo.Font]*opentype.Font
+}
+
+// NewRenderer creates a Renderer, parsing the fonts in opts.
+func NewRenderer(opts Options) (*Renderer, error) {
+	data := map[imgflipgo.Font][]byte{
+		imgflipgo.FontImpact: gobold.TTF,
+		imgflipgo.FontArial:  goregular.TTF,
+	}
+	for name, ttf := range opts.Fonts {
+		data[name] = ttf
+	}
+
+	r := &Renderer{fonts: map[imgflipgo.Font]*opentype.Font{}}
+	for name, ttf := range data {
+		parsed, err := opentype.Parse(ttf)
+		if err != nil {
+			return nil, fmt.Errorf("parsing font %s: %w", name, err)
+		}
+		r.fonts[name] = parsed
+	}
+	return r, nil
+}
+
+// Render draws the captions in req onto a copy of template, using the same
+// semantics as the caption_image endpoint:
+//
+//   - TopText and BottomText are converted to uppercase and drawn at the top and
+//     bottom of the image. They are ignored if TextBoxes are specified.
+//   - TextBoxes are drawn as-is, at their X, Y, Width, and Height if specified.
+//     Otherwise the first box is drawn at the top, the last at the bottom, and
+//     any others are spaced evenly between them.
+//   - Text is wrapped to fit its box, starting at MaxFontSizePx (or
+//     imgflipgo.DefaultMaxFontSizePx) and shrinking as needed.
+//   - Text is drawn in Color with an OutlineColor outline, defaulting to white
+//     with a black outline.
+//
+// Credentials and the template ID in req are ignored.
+func (r *Renderer) Render(template image.Image, req *imgflipgo.CaptionRequest) (*image.RGBA, error) {
+	if req == nil {
+		return nil, imgflipgo.ErrNilRequest
+	}
+	if template == nil {
+		return nil, errors.New("nil template provided")
+	}
+
+	fontName := imgflipgo.FontImpact
+	if req.Font != nil {
+		fontName = *req.Font
+	}
+	fnt, ok := r.fonts[fontName]
+	if !ok {
+		return nil, fmt.Errorf("unknown font %q", fontName)
+	}
+
+	maxSize := imgflipgo.DefaultMaxFontSizePx
+	if req.MaxFontSizePx != nil && *req.MaxFontSizePx > 0 {
+		maxSize = *req.MaxFontSizePx
+	}
+
+	bounds := template.Bounds()
+	canvas := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(canvas, canvas.Bounds(), template, bounds.Min, draw.Src)
+
+	for _, c := range layout(req, canvas.Bounds()) {
+		err := drawCaption(canvas, fnt, maxSize, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return canvas, nil
+}
+
+// defaultRenderer is used by Render, and created on first use.
+var (
+	defaultRendererOnce sync.Once
+	defaultRenderer     *Renderer
+	defaultRendererErr  error
+)
+
+// Render draws req onto template with the default fonts. See Renderer.Render.
+func Render(template image.Image, req *imgflipgo.CaptionRequest) (*image.RGBA, error) {
+	defaultRendererOnce.Do(func() {
+		defaultRenderer, defaultRendererErr = NewRenderer(Options{})
+	})
+	if defaultRendererErr != nil {
+		return nil, defaultRendererErr
+	}
+	return defaultRenderer.Render(template, req)
+}
+
+// vAlign is where text sits vertically within its box.
+type vAlign int
+
+const (
+	alignTop vAlign = iota
+	alignMiddle
+	alignBottom
+)
+
+// caption is a piece of text positioned within the image.
+type caption struct {
+	text         string
+	box          image.Rectangle
+	align        vAlign
+	color        imgflipgo.Color
+	outlineColor imgflipgo.Color
+}
+
+// layout positions each piece of text in req within bounds.
+func layout(req *imgflipgo.CaptionRequest, bounds image.Rectangle) []caption {
+	w, h := bounds.Dx(), bounds.Dy()
+	pad := w / 50
+	band := h / 4
+	top := image.Rect(pad, pad, w-pad, pad+band)
+	bottom := image.Rect(pad, h-pad-band, w-pad, h-pad)
+
+	if len(req.TextBoxes) == 0 {
+		var captions []caption
+		if req.TopText != nil {
+			captions = append(captions, caption{text: strings.ToUpper(*req.TopText), box: top, align: alignTop})
+		}
+		if req.BottomText != nil {
+			captions = append(captions, caption{text: strings.ToUpper(*req.BottomText), box: bottom, align: alignBottom})
+		}
+		for i := range captions {
+			captions[i].color = DefaultColor
+			captions[i].outlineColor = DefaultOutlineColor
+		}
+		return captions
+	}
+
+	n := len(req.TextBoxes)
+	captions := make([]caption, 0, n)
+	for i, tb := range req.TextBoxes {
+		c := caption{text: tb.Text, color: DefaultColor, outlineColor: DefaultOutlineColor}
+		if tb.Color != nil {
+			c.color = *tb.Color
+		}
+		if tb.OutlineColor != nil {
+			c.outlineColor = *tb.OutlineColor
+		}
+
+		switch {
+		case tb.X != nil && tb.Y != nil && tb.Width != nil && tb.Height != nil:
+			c.box = image.Rect(int(*tb.X), int(*tb.Y), int(*tb.X+*tb.Width), int(*tb.Y+*tb.Height))
+			c.align = alignMiddle
+		case i == 0:
+			c.box = top
+			c.align = alignTop
+		case i == n-1:
+			c.box = bottom
+			c.align = alignBottom
+		default:
+			// Space the middle boxes evenly between the top and bottom bands.
+			slot := (bottom.Min.Y - top.Max.Y) / (n - 2)
+			y := top.Max.Y + (i-1)*slot
+			c.box = image.Rect(pad, y, w-pad, y+slot)
+			c.align = alignMiddle
+		}
+		captions = append(captions, c)
+	}
+	return captions
+}
+
+// drawCaption wraps and draws c onto dst, at the largest font size up to
+// maxSize at which the text fits within c.box.
+func drawCaption(dst draw.Image, fnt *opentype.Font, maxSize uint, c caption) error {
+	if strings.TrimSpace(c.text) == "" || c.box.Empty() {
+		return nil
+	}
+
+	// Text that fits at one size fits at any smaller one, so binary search for
+	// the size. A line is at least as tall as the font size, so sizes taller
+	// than the box never fit.
+	lo, hi := minFontSizePx, int(maxSize)
+	if hi > c.box.Dy() {
+		hi = c.box.Dy()
+	}
+	if int(maxSize) < lo {
+		lo, hi = int(maxSize), int(maxSize)
+	} else if hi < lo {
+		hi = lo
+	}
+	for lo < hi {
+		size := (lo + hi + 1) / 2
+		face, err := newFace(fnt, size)
+		if err != nil {
+			return err
+		}
+		ok := fits(face, wrap(face, c.text, c.box.Dx()), c.box)
+		face.Close()
+		if ok {
+			lo = size
+		} else {
+			hi = size - 1
+		}
+	}
+
+	face, err := newFace(fnt, lo)
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+	lines := wrap(face, c.text, c.box.Dx())
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil()
+	textHeight := lineHeight * len(lines)
+
+	y := c.box.Min.Y
+	switch c.align {
+	case alignMiddle:
+		y += (c.box.Dy() - textHeight) / 2
+	case alignBottom:
+		y = c.box.Max.Y - textHeight
+	}
+
+	outline := image.NewUniform(c.outlineColor)
+	fill := image.NewUniform(c.color)
+	radius := lineHeight/16 + 1
+
+	for i, line := range lines {
+		width := font.MeasureString(face, line).Ceil()
+		x := c.box.Min.X + (c.box.Dx()-width)/2
+		baseline := y + i*lineHeight + metrics.Ascent.Ceil()
+
+		d := font.Drawer{Dst: dst, Face: face}
+		d.Src = outline
+		for dx := -radius; dx <= radius; dx++ {
+			for dy := -radius; dy <= radius; dy++ {
+				if dx*dx+dy*dy > radius*radius {
+					continue
+				}
+				d.Dot = fixed.P(x+dx, baseline+dy)
+				d.DrawString(line)
+			}
+		}
+		d.Src = fill
+		d.Dot = fixed.P(x, baseline)
+		d.DrawString(line)
+	}
+	return nil
+}
+
+func newFace(fnt *opentype.Font, size int) (font.Face, error) {
+	return opentype.NewFace(fnt, &opentype.FaceOptions{Size: float64(size), DPI: 72, Hinting: font.HintingFull})
+}
+
+// wrap splits text into lines no wider than width, breaking between words and
+// at explicit newlines. A single word wider than width gets a line to itself.
+func wrap(face font.Face, text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && font.MeasureString(face, candidate).Ceil() > width {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line = candidate
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func fits(face font.Face, lines []string, box image.Rectangle) bool {
+	if face.Metrics().Height.Ceil()*len(lines) > box.Dy() {
+		return false
+	}
+	for _, line := range lines {
+		if font.MeasureString(face, line).Ceil() > box.Dx() {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode writes img to w in the given format.
+func Encode(w io.Writer, img image.Image, format Format) error {
+	switch format {
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 90})
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// LoadTemplate decodes a PNG, JPEG, or GIF template image from r.
+func LoadTemplate(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// LoadTemplateFile decodes a PNG, JPEG, or GIF template image from a file.
+func LoadTemplateFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadTemplate(f)
+}
+
+// FetchTemplate downloads and decodes the template image at meme.URL with
+// client, or imgflipgo.DefaultClient if client is nil. See
+// imgflipgo.Client.FetchImage.
+func FetchTemplate(ctx context.Context, client *imgflipgo.Client, meme imgflipgo.Meme) (image.Image, error) {
+	if client == nil {
+		client = imgflipgo.DefaultClient
+	}
+	img, err := client.FetchImage(ctx, meme.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching template %s: %w", meme.ID, err)
+	}
+	return img, nil
+}
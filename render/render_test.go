@@ -0,0 +1,199 @@
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+	"github.com/Kardbord/imgflipgo/v2/render"
+)
+
+func newTemplate(w, h int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+// countColor counts the pixels in r that are exactly c.
+func countColor(img image.Image, r image.Rectangle, c color.RGBA) int {
+	n := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+var (
+	gray  = color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xFF}
+	white = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	black = color.RGBA{A: 0xFF}
+	red   = color.RGBA{R: 0xFF, A: 0xFF}
+)
+
+func TestRenderTopAndBottom(t *testing.T) {
+	template := newTemplate(400, 400, gray)
+	req := (&imgflipgo.CaptionRequest{}).SetTopText("top text").SetBottomText("bottom text")
+
+	img, err := render.Render(template, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != template.Bounds() {
+		t.Fatalf("expected bounds %v, got %v", template.Bounds(), img.Bounds())
+	}
+
+	top := image.Rect(0, 0, 400, 100)
+	middle := image.Rect(0, 150, 400, 250)
+	bottom := image.Rect(0, 300, 400, 400)
+	for name, r := range map[string]image.Rectangle{"top": top, "bottom": bottom} {
+		if countColor(img, r, white) == 0 {
+			t.Errorf("expected white text in the %s of the image", name)
+		}
+		if countColor(img, r, black) == 0 {
+			t.Errorf("expected a black outline in the %s of the image", name)
+		}
+	}
+	if n := countColor(img, middle, gray); n != middle.Dx()*middle.Dy() {
+		t.Errorf("expected the middle of the image to be untouched, %d pixels changed", middle.Dx()*middle.Dy()-n)
+	}
+
+	// The template itself must not be modified.
+	if n := countColor(template, template.Bounds(), gray); n != 400*400 {
+		t.Error("template was modified")
+	}
+}
+
+func TestRenderTextBoxes(t *testing.T) {
+	template := newTemplate(400, 400, gray)
+	req := &imgflipgo.CaptionRequest{
+		TextBoxes: []imgflipgo.TextBox{
+			*(&imgflipgo.TextBox{Text: "positioned"}).
+				SetX(200).SetY(200).SetWidth(200).SetHeight(200).
+				SetColor(0xFF0000),
+		},
+	}
+
+	img, err := render.Render(template, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countColor(img, image.Rect(200, 200, 400, 400), red) == 0 {
+		t.Error("expected red text within the text box")
+	}
+	if countColor(img, image.Rect(0, 0, 400, 200), red) != 0 {
+		t.Error("expected no text outside the text box")
+	}
+}
+
+func TestRenderShrinksToFit(t *testing.T) {
+	template := newTemplate(200, 200, gray)
+	box := image.Rect(10, 10, 110, 40)
+	req := &imgflipgo.CaptionRequest{
+		TextBoxes: []imgflipgo.TextBox{
+			*(&imgflipgo.TextBox{Text: "a rather long caption that has to shrink"}).
+				SetX(uint(box.Min.X)).SetY(uint(box.Min.Y)).SetWidth(uint(box.Dx())).SetHeight(uint(box.Dy())).
+				SetOutlineColor(0x808080),
+		},
+	}
+
+	img, err := render.Render(template, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countColor(img, box, white) == 0 {
+		t.Error("expected text within the text box")
+	}
+	outside := countColor(img, img.Bounds(), white) - countColor(img, box, white)
+	if outside != 0 {
+		t.Errorf("expected text to fit within the text box, %d pixels outside", outside)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	template := newTemplate(10, 10, gray)
+	_, err := render.Render(template, nil)
+	if !errors.Is(err, imgflipgo.ErrNilRequest) {
+		t.Errorf("expected ErrNilRequest, got %v", err)
+	}
+
+	req := (&imgflipgo.CaptionRequest{}).SetTopText("text").SetFont("comic sans")
+	_, err = render.Render(template, req)
+	if err == nil {
+		t.Error("expected an error for an unknown font")
+	}
+
+	_, err = render.NewRenderer(render.Options{Fonts: map[imgflipgo.Font][]byte{imgflipgo.FontImpact: []byte("not a font")}})
+	if err == nil {
+		t.Error("expected an error for invalid font data")
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	template := newTemplate(50, 50, gray)
+	for _, format := range []render.Format{render.FormatPNG, render.FormatJPEG} {
+		buf := bytes.Buffer{}
+		err := render.Encode(&buf, template, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		img, err := render.LoadTemplate(&buf)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if img.Bounds() != template.Bounds() {
+			t.Errorf("%s: expected bounds %v, got %v", format, template.Bounds(), img.Bounds())
+		}
+	}
+
+	err := render.Encode(&bytes.Buffer{}, template, "bmp")
+	if err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestFetchTemplate(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+
+	// The fake server only serves images it has captioned.
+	client := srv.NewClient()
+	resp, err := client.CaptionImage((&imgflipgo.CaptionRequest{TemplateID: imgflipgotest.Memes[0].ID}).SetTopText("top"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	meme := imgflipgo.Meme{ID: imgflipgotest.Memes[0].ID, URL: resp.Data.URL}
+
+	template, err := render.FetchTemplate(context.Background(), client, meme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.Bounds().Dx() != imgflipgotest.ImageWidth || template.Bounds().Dy() != imgflipgotest.ImageHeight {
+		t.Errorf("expected a %dx%d template, got %v", imgflipgotest.ImageWidth, imgflipgotest.ImageHeight, template.Bounds())
+	}
+
+	_, err = render.FetchTemplate(context.Background(), srv.NewClient(imgflipgo.WithMaxImageSize(16)), meme)
+	if !errors.Is(err, imgflipgo.ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	template := newTemplate(500, 500, gray)
+	req := (&imgflipgo.CaptionRequest{}).SetTopText("one does not simply").SetBottomText("render a caption")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := render.Render(template, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
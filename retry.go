@@ -0,0 +1,217 @@
+package imgflipgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that fail due to transport
+// errors or retryable HTTP statuses. The zero value disables retries.
+//
+// Note that a retried caption request may result in more than one image being
+// created by imgflip.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made, including the first.
+	// Values less than 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Defaults to 500ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. It does not apply to delays
+	// requested by the server with a Retry-After header. Defaults to 30s.
+	MaxBackoff time.Duration
+
+	// Multiplier is applied to the delay after each retry. Defaults to 2.
+	Multiplier float64
+
+	// Jitter is the fraction, between 0 and 1, of each delay that is randomized
+	// to avoid many clients retrying in lockstep.
+	Jitter float64
+
+	// RetryableStatus reports whether a response with the given HTTP status
+	// should be retried. Defaults to DefaultRetryableStatus.
+	RetryableStatus func(statusCode int) bool
+
+	// [optional] OnRetry is called before each retry, e.g. for logging.
+	OnRetry func(RetryEvent)
+}
+
+// RetryEvent describes a failed attempt that is about to be retried.
+type RetryEvent struct {
+	// Endpoint being requested, e.g. "caption_image".
+	Endpoint string
+
+	// Attempt is the number of the attempt that failed, starting from 1.
+	Attempt int
+
+	// Err is the error the attempt failed with.
+	Err error
+
+	// Delay is how long the Client will wait before the next attempt.
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy returns a RetryPolicy that makes up to 3 attempts with
+// exponential backoff and jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// DefaultRetryableStatus reports whether statusCode is 429 Too Many Requests or
+// a 5xx server error.
+func DefaultRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// WithRetryPolicy sets the RetryPolicy used for every request. By default,
+// requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	if p.RetryableStatus != nil {
+		return p.RetryableStatus(statusCode)
+	}
+	return DefaultRetryableStatus(statusCode)
+}
+
+// backoff returns the delay before the retry following the given attempt.
+// A positive retryAfter requested by the server takes precedence.
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if delay > float64(maxBackoff) {
+		delay = float64(maxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay -= delay * jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// parseRetryAfter parses a Retry-After header, which may be either a number of
+// seconds or an HTTP date. Zero is returned if the header is absent or invalid.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+// statusError creates an *APIError for a response with a failing HTTP status,
+// including the API's error message if the body contains one.
+func statusError(endpoint string, resp *response) *APIError {
+	body := struct {
+		ErrorMsg string `json:"error_message"`
+	}{}
+	json.Unmarshal(resp.Body, &body)
+	return newAPIError(endpoint, resp, body.ErrorMsg)
+}
+
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryError is returned when a request still fails after being retried. It
+// preserves the error from every attempt, and unwraps to Err if set, or
+// otherwise the last attempt's error.
+type RetryError struct {
+	// Endpoint being requested, e.g. "caption_image".
+	Endpoint string
+
+	// Attempts holds the error from each HTTP request made, in order.
+	Attempts []error
+
+	// Err is what stopped retrying between attempts, e.g. the context being
+	// canceled during a backoff or waiting for the rate limiter. It is nil if
+	// retrying stopped because the last attempt failed.
+	Err error
+}
+
+// newRetryError returns a *RetryError for the given attempts and the error that
+// stopped retrying, if any. If there is only one error, it is returned as is.
+func newRetryError(endpoint string, attempts []error, err error) error {
+	if err == nil && len(attempts) == 1 {
+		return attempts[0]
+	}
+	if len(attempts) == 0 {
+		return err
+	}
+	return &RetryError{Endpoint: endpoint, Attempts: attempts, Err: err}
+}
+
+func (e *RetryError) Error() string {
+	noun := "attempts"
+	if len(e.Attempts) == 1 {
+		noun = "attempt"
+	}
+	return fmt.Sprintf("%s request failed after %d %s: %v", e.Endpoint, len(e.Attempts), noun, e.Unwrap())
+}
+
+func (e *RetryError) Unwrap() error {
+	if e.Err != nil {
+		return e.Err
+	}
+	if len(e.Attempts) == 0 {
+		return nil
+	}
+	return e.Attempts[len(e.Attempts)-1]
+}
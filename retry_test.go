@@ -0,0 +1,140 @@
+package imgflipgo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+)
+
+func fastRetryPolicy(maxAttempts int) imgflipgo.RetryPolicy {
+	return imgflipgo.RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Jitter:         0.5,
+	}
+}
+
+func TestRetryRecovers(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	srv.FailNext(2, http.StatusServiceUnavailable)
+
+	var events []imgflipgo.RetryEvent
+	policy := fastRetryPolicy(3)
+	policy.OnRetry = func(event imgflipgo.RetryEvent) {
+		events = append(events, event)
+	}
+
+	resp, err := srv.NewClient(imgflipgo.WithRetryPolicy(policy)).CaptionImage((&imgflipgo.CaptionRequest{
+		TemplateID: testTemplateID,
+	}).SetTopText("Top Text"))
+	expectSuccess(t, resp, err)
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 retries, got %d", len(events))
+	}
+	for i, event := range events {
+		if event.Attempt != i+1 || event.Endpoint != "caption_image" {
+			t.Errorf("unexpected retry event %+v", event)
+		}
+		var apiErr *imgflipgo.APIError
+		if !errors.As(event.Err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected a 503 *APIError, got %v", event.Err)
+		}
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	srv.FailNext(5, http.StatusBadGateway)
+
+	_, err := srv.NewClient(imgflipgo.WithRetryPolicy(fastRetryPolicy(3))).GetMemes()
+	var retryErr *imgflipgo.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected a *RetryError, got %T: %v", err, err)
+	}
+	if len(retryErr.Attempts) != 3 {
+		t.Fatalf("expected 3 attempt errors, got %d", len(retryErr.Attempts))
+	}
+	var apiErr *imgflipgo.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("expected RetryError to unwrap to a 502 *APIError, got %v", err)
+	}
+}
+
+func TestRetryCanceledDuringBackoff(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	srv.FailNext(5, http.StatusBadGateway)
+
+	policy := fastRetryPolicy(3)
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := srv.NewClient(imgflipgo.WithRetryPolicy(policy)).GetMemesContext(ctx)
+	var retryErr *imgflipgo.RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected a *RetryError, got %T: %v", err, err)
+	}
+	if len(retryErr.Attempts) != 1 {
+		t.Errorf("expected only the request to count as an attempt, got %d", len(retryErr.Attempts))
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "after 1 attempt:") {
+		t.Errorf("expected the backoff to be interrupted after 1 attempt, got %v", err)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	srv := imgflipgotest.NewServer()
+	defer srv.Close()
+	srv.FailNext(1, http.StatusBadRequest)
+
+	_, err := srv.NewClient(imgflipgo.WithRetryPolicy(fastRetryPolicy(3))).GetMemes()
+	var apiErr *imgflipgo.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a 400 *APIError, got %v", err)
+	}
+	var retryErr *imgflipgo.RetryError
+	if errors.As(err, &retryErr) {
+		t.Fatal("did not expect a 400 to be retried")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"memes":[]}}`))
+	}))
+	defer srv.Close()
+
+	var delay time.Duration
+	policy := fastRetryPolicy(2)
+	policy.OnRetry = func(event imgflipgo.RetryEvent) {
+		delay = event.Delay
+	}
+
+	_, err := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL), imgflipgo.WithRetryPolicy(policy)).GetMemes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delay != time.Second {
+		t.Fatalf("expected Retry-After to set a 1s delay, got %v", delay)
+	}
+}
@@ -0,0 +1,126 @@
+package imgflipgo
+
+import (
+	"context"
+	"net/url"
+)
+
+const SearchMemesEndpoint string = DefaultBaseURL + "/" + searchMemesPath
+
+// SearchRequest specifies parameters for the search_memes endpoint.
+type SearchRequest struct {
+	// Username of a valid imgflip account. The search_memes endpoint requires
+	// authentication.
+	Username string `json:"username,omitempty"`
+
+	// Password for the imgflip account.
+	Password string `json:"password,omitempty"`
+
+	// Text to search template names for.
+	Query string `json:"query,omitempty"`
+
+	// [optional] Include templates flagged as NSFW in the results.
+	IncludeNSFW bool `json:"include_nsfw,omitempty"`
+}
+
+// SearchOption sets an optional SearchRequest parameter.
+type SearchOption func(*SearchRequest)
+
+// SearchCredentials sets the imgflip account used for a search. If not provided,
+// the Client's credentials are used.
+func SearchCredentials(username, password string) SearchOption {
+	return func(sr *SearchRequest) {
+		sr.Username = username
+		sr.Password = password
+	}
+}
+
+// SearchIncludeNSFW sets whether NSFW templates may be included in the results.
+func SearchIncludeNSFW(include bool) SearchOption {
+	return func(sr *SearchRequest) {
+		sr.IncludeNSFW = include
+	}
+}
+
+// AppendForm appends the application/x-www-form-urlencoded encoding of sr to
+// dst and returns the extended buffer, like CaptionRequest.AppendForm.
+func (sr SearchRequest) AppendForm(dst []byte) []byte {
+	w := newFormWriter(dst)
+	if sr.IncludeNSFW {
+		w.string("include_nsfw", "1")
+	}
+	if sr.Password != "" {
+		w.string("password", sr.Password)
+	}
+	w.string("query", sr.Query)
+	if sr.Username != "" {
+		w.string("username", sr.Username)
+	}
+	return w.buf
+}
+
+func (sr SearchRequest) CreateHTTPFormBody() (url.Values, error) {
+	return url.ParseQuery(string(sr.AppendForm(nil)))
+}
+
+// SearchMemesWithResponse wraps the search_memes endpoint using DefaultClient.
+func SearchMemesWithResponse(query string, opts ...SearchOption) (*MemesResponse, error) {
+	return DefaultClient.SearchMemesWithResponse(query, opts...)
+}
+
+// SearchMemesWithResponseContext is like SearchMemesWithResponse, but the request is bound to ctx.
+func SearchMemesWithResponseContext(ctx context.Context, query string, opts ...SearchOption) (*MemesResponse, error) {
+	return DefaultClient.SearchMemesWithResponseContext(ctx, query, opts...)
+}
+
+// SearchMemes returns the memes matching query using DefaultClient.
+func SearchMemes(query string, opts ...SearchOption) ([]Meme, error) {
+	return DefaultClient.SearchMemes(query, opts...)
+}
+
+// SearchMemesContext is like SearchMemes, but the request is bound to ctx.
+func SearchMemesContext(ctx context.Context, query string, opts ...SearchOption) ([]Meme, error) {
+	return DefaultClient.SearchMemesContext(ctx, query, opts...)
+}
+
+// SearchMemesWithResponse wraps the search_memes endpoint.
+func (c *Client) SearchMemesWithResponse(query string, opts ...SearchOption) (*MemesResponse, error) {
+	return c.SearchMemesWithResponseContext(context.Background(), query, opts...)
+}
+
+// SearchMemesWithResponseContext is like SearchMemesWithResponse, but the request
+// is bound to ctx. If the API reports that the request failed, the response is
+// returned along with an *APIError.
+func (c *Client) SearchMemesWithResponseContext(ctx context.Context, query string, opts ...SearchOption) (*MemesResponse, error) {
+	req := SearchRequest{Query: query}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	req.Username, req.Password = c.credentials(req.Username, req.Password)
+
+	memesResp := MemesResponse{}
+	resp, err := c.call(ctx, searchMemesPath, req.AppendForm(nil), &memesResp)
+	if err != nil {
+		return nil, err
+	}
+	if !memesResp.Success {
+		return &memesResp, newAPIError(searchMemesPath, resp, memesResp.ErrorMsg)
+	}
+
+	return &memesResp, nil
+}
+
+// SearchMemes returns the memes matching query, or an error if the request
+// was unsuccessful.
+func (c *Client) SearchMemes(query string, opts ...SearchOption) ([]Meme, error) {
+	return c.SearchMemesContext(context.Background(), query, opts...)
+}
+
+// SearchMemesContext is like SearchMemes, but the request is bound to ctx.
+func (c *Client) SearchMemesContext(ctx context.Context, query string, opts ...SearchOption) ([]Meme, error) {
+	memesResp, err := c.SearchMemesWithResponseContext(ctx, query, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return memesResp.Data.Memes, nil
+}
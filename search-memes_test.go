@@ -0,0 +1,72 @@
+package imgflipgo_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func newSearchServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/search_memes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.PostFormValue("username") != "search_user" || r.PostFormValue("password") != "search_pass" {
+			fmt.Fprint(w, `{"success":false,"error_message":"Invalid username/password"}`)
+			return
+		}
+		if r.PostFormValue("query") != "drake" {
+			t.Errorf("unexpected query %q", r.PostFormValue("query"))
+		}
+		if r.PostFormValue("include_nsfw") == "1" {
+			fmt.Fprint(w, `{"success":true,"data":{"memes":[{"id":"181913649","name":"Drake Hotline Bling"},{"id":"1","name":"NSFW Drake"}]}}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":true,"data":{"memes":[{"id":"181913649","name":"Drake Hotline Bling"}]}}`)
+	}))
+}
+
+func TestSearchMemes(t *testing.T) {
+	srv := newSearchServer(t)
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL), imgflipgo.WithCredentials("search_user", "search_pass"))
+	memes, err := client.SearchMemes("drake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != 1 || memes[0].ID != "181913649" {
+		t.Fatalf("unexpected memes %+v", memes)
+	}
+
+	memes, err = client.SearchMemes("drake", imgflipgo.SearchIncludeNSFW(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memes) != 2 {
+		t.Fatalf("expected NSFW results to be included, got %+v", memes)
+	}
+}
+
+func TestSearchMemesInvalidAuth(t *testing.T) {
+	srv := newSearchServer(t)
+	defer srv.Close()
+
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL(srv.URL))
+	resp, err := client.SearchMemesWithResponse("drake", imgflipgo.SearchCredentials("search_user", "wrong"))
+	if !errors.Is(err, imgflipgo.ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if resp == nil || resp.Success || resp.ErrorMsg == "" {
+		t.Fatalf("expected an unsuccessful response with an error message, got %+v", resp)
+	}
+
+	_, err = client.SearchMemes("drake", imgflipgo.SearchCredentials("search_user", "wrong"))
+	if err == nil || err.Error() != resp.ErrorMsg {
+		t.Fatalf("expected error %q, got %v", resp.ErrorMsg, err)
+	}
+}
@@ -0,0 +1,248 @@
+// Package server exposes imgflipgo as a JSON HTTP API, so that clients can
+// caption and search templates without imgflip credentials or form encoding.
+//
+// A Server handles:
+//
+//	POST /caption             caption a template from a CaptionRequest JSON body
+//	GET  /memes               list the cached get_memes templates
+//	GET  /memes/search?q=...  fuzzy search the cached templates, see MemeIndex
+//
+// Credentials are supplied by the Server's Client; any in a request body are
+// ignored. Errors are returned as {"error": "..."} with a status code that
+// reflects their cause, see StatusCode.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+// DefaultCatalogTTL is how long templates are cached by a Server's catalog,
+// unless WithCatalog is used.
+const DefaultCatalogTTL = time.Hour
+
+// DefaultMaxBodySize is the largest request body a Server accepts, unless
+// WithMaxBodySize is used.
+const DefaultMaxBodySize int64 = 1 << 20
+
+// DefaultSearchLimit is the number of matches returned by /memes/search when
+// the request doesn't specify a limit.
+const DefaultSearchLimit = 10
+
+// MaxSearchLimit is the largest limit /memes/search accepts.
+const MaxSearchLimit = 100
+
+// Server is an http.Handler serving the JSON API. It is safe for concurrent
+// use.
+type Server struct {
+	client      *imgflipgo.Client
+	catalog     *imgflipgo.MemeCatalog
+	maxBodySize int64
+	mux         *http.ServeMux
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithCatalog sets the catalog that templates are served from. By default, a
+// catalog with DefaultCatalogTTL is created for the Server's Client.
+func WithCatalog(catalog *imgflipgo.MemeCatalog) Option {
+	return func(s *Server) {
+		s.catalog = catalog
+	}
+}
+
+// WithMaxBodySize sets the largest request body the Server accepts.
+func WithMaxBodySize(n int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = n
+	}
+}
+
+// New creates a Server that makes requests with client, or
+// imgflipgo.DefaultClient if client is nil. The client should be configured
+// with imgflipgo.WithCredentials, since requests to the Server don't carry any.
+func New(client *imgflipgo.Client, opts ...Option) *Server {
+	if client == nil {
+		client = imgflipgo.DefaultClient
+	}
+	s := &Server{
+		client:      client,
+		maxBodySize: DefaultMaxBodySize,
+		mux:         http.NewServeMux(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.catalog == nil {
+		s.catalog = imgflipgo.NewMemeCatalog(client, DefaultCatalogTTL)
+	}
+
+	s.mux.HandleFunc("/caption", allow(http.MethodPost, s.handleCaption))
+	s.mux.HandleFunc("/memes", allow(http.MethodGet, s.handleMemes))
+	s.mux.HandleFunc("/memes/search", allow(http.MethodGet, s.handleSearch))
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no such endpoint %s", r.URL.Path))
+	})
+	return s
+}
+
+// Catalog returns the catalog that templates are served from.
+func (s *Server) Catalog() *imgflipgo.MemeCatalog {
+	return s.catalog
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// CaptionResponse is the body of a successful POST /caption.
+type CaptionResponse struct {
+	URL     string `json:"url"`
+	PageURL string `json:"page_url"`
+}
+
+// MemesResponse is the body of a successful GET /memes.
+type MemesResponse struct {
+	Memes []imgflipgo.Meme `json:"memes"`
+}
+
+// Match is a template matched by GET /memes/search.
+type Match struct {
+	Meme  imgflipgo.Meme `json:"meme"`
+	Score float64        `json:"score"`
+}
+
+// SearchResponse is the body of a successful GET /memes/search.
+type SearchResponse struct {
+	Matches []Match `json:"matches"`
+}
+
+// ErrorResponse is the body of every failed request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleCaption(w http.ResponseWriter, r *http.Request) {
+	req := imgflipgo.CaptionRequest{}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	// The Client's credentials are always used.
+	req.Username, req.Password = "", ""
+	resp, err := s.client.CaptionImageContext(r.Context(), &req)
+	if err != nil {
+		writeError(w, StatusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, CaptionResponse{URL: resp.Data.URL, PageURL: resp.Data.PageURL})
+}
+
+func (s *Server) handleMemes(w http.ResponseWriter, r *http.Request) {
+	memes, err := s.catalog.Memes(r.Context())
+	if err != nil {
+		writeError(w, StatusCode(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, MemesResponse{Memes: memes})
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing query parameter q"))
+		return
+	}
+	limit := DefaultSearchLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 1 || n > MaxSearchLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be from 1 to %d", MaxSearchLimit))
+			return
+		}
+		limit = n
+	}
+
+	idx, err := s.catalog.Index(r.Context())
+	if err != nil {
+		writeError(w, StatusCode(err), err)
+		return
+	}
+	matches := []Match{}
+	for _, match := range idx.Search(query, limit) {
+		matches = append(matches, Match{Meme: match.Meme, Score: match.Score})
+	}
+	writeJSON(w, http.StatusOK, SearchResponse{Matches: matches})
+}
+
+// StatusClientClosedRequest is the nonstandard status code, popularized by
+// nginx, for requests the client gave up on before a response was written.
+const StatusClientClosedRequest = 499
+
+// StatusCode returns the HTTP status code a Server responds with for err:
+//
+//   - 400 Bad Request for an invalid request (imgflipgo.ErrInvalidRequest)
+//   - 404 Not Found for an unknown template (imgflipgo.ErrTemplateNotFound)
+//   - 429 Too Many Requests if a rate limit was hit, locally
+//     (imgflipgo.ErrRateLimited) or by imgflip
+//   - 499 Client Closed Request if the request was canceled, e.g. because the
+//     client disconnected (context.Canceled)
+//   - 504 Gateway Timeout if the request's deadline passed
+//     (context.DeadlineExceeded), or imgflip timed out
+//   - 502 Bad Gateway for any other failure talking to imgflip, including an
+//     *imgflipgo.APIError
+func StatusCode(err error) int {
+	apiErr := &imgflipgo.APIError{}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, imgflipgo.ErrInvalidRequest), errors.Is(err, imgflipgo.ErrNilRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, imgflipgo.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, imgflipgo.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.Canceled):
+		return StatusClientClosedRequest
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+// allow wraps handler, responding 405 Method Not Allowed to requests that
+// don't use method.
+func allow(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,225 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+	"github.com/Kardbord/imgflipgo/v2/server"
+)
+
+// newServer starts a Server backed by a fake imgflip API.
+func newServer(t *testing.T) (*httptest.Server, *imgflipgotest.Server) {
+	t.Helper()
+	api := imgflipgotest.NewServer()
+	t.Cleanup(api.Close)
+	srv := httptest.NewServer(server.New(api.NewClient()))
+	t.Cleanup(srv.Close)
+	return srv, api
+}
+
+// do sends a request to srv and decodes the JSON response into v, returning the
+// status code.
+func do(t *testing.T, srv *httptest.Server, method, path, body string, v interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: expected a JSON response, got %q", method, path, ct)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != nil {
+		err = json.Unmarshal(data, v)
+		if err != nil {
+			t.Fatalf("%s %s: %v: %s", method, path, err, data)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestCaption(t *testing.T) {
+	srv, api := newServer(t)
+
+	body := fmt.Sprintf(`{"template_id": %q, "username": "someone", "password": "else", "boxes": [{"text": "a", "color": "#ffa500"}, {"text": "b"}]}`, imgflipgotest.Memes[0].ID)
+	out := server.CaptionResponse{}
+	status := do(t, srv, http.MethodPost, "/caption", body, &out)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if out.URL == "" || out.PageURL == "" {
+		t.Errorf("unexpected response %+v", out)
+	}
+
+	requests := api.Requests()
+	req := requests[len(requests)-1]
+	username, password := api.Credentials()
+	if req.Form.Get("username") != username || req.Form.Get("password") != password {
+		t.Errorf("expected the server's credentials to be used, got %q, %q", req.Form.Get("username"), req.Form.Get("password"))
+	}
+	if len(req.TextBoxes) != 2 || *req.TextBoxes[0].Color != 0xFFA500 {
+		t.Errorf("unexpected text boxes %+v", req.TextBoxes)
+	}
+}
+
+func TestCaptionErrors(t *testing.T) {
+	srv, api := newServer(t)
+	id := imgflipgotest.Memes[0].ID
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"malformed JSON", http.MethodPost, `{"template_id":`, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, `{"template_id": "1", "top_txt": "a"}`, http.StatusBadRequest},
+		{"invalid request", http.MethodPost, `{"template_id": "` + id + `"}`, http.StatusBadRequest},
+		{"unknown template", http.MethodPost, `{"template_id": "1", "text0": "a"}`, http.StatusNotFound},
+		{"wrong method", http.MethodGet, "", http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		out := server.ErrorResponse{}
+		status := do(t, srv, test.method, "/caption", test.body, &out)
+		if status != test.status {
+			t.Errorf("%s: expected %d, got %d: %s", test.name, test.status, status, out.Error)
+		}
+		if out.Error == "" {
+			t.Errorf("%s: expected an error message", test.name)
+		}
+	}
+
+	api.FailNext(1, http.StatusInternalServerError)
+	out := server.ErrorResponse{}
+	status := do(t, srv, http.MethodPost, "/caption", `{"template_id": "`+id+`", "text0": "a"}`, &out)
+	if status != http.StatusBadGateway {
+		t.Errorf("expected 502 for an API failure, got %d: %s", status, out.Error)
+	}
+
+	if status := do(t, srv, http.MethodGet, "/nope", "", nil); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown endpoint, got %d", status)
+	}
+}
+
+func TestMemes(t *testing.T) {
+	srv, api := newServer(t)
+
+	for i := 0; i < 2; i++ {
+		out := server.MemesResponse{}
+		status := do(t, srv, http.MethodGet, "/memes", "", &out)
+		if status != http.StatusOK {
+			t.Fatalf("expected 200, got %d", status)
+		}
+		if len(out.Memes) != len(imgflipgotest.Memes) {
+			t.Errorf("expected %d memes, got %d", len(imgflipgotest.Memes), len(out.Memes))
+		}
+	}
+
+	fetches := 0
+	for _, req := range api.Requests() {
+		if req.Endpoint == "get_memes" {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected the memes to be cached, got %d get_memes requests", fetches)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	catalog := imgflipgo.NewMemeCatalog(api.NewClient(), time.Hour)
+	srv := httptest.NewServer(server.New(api.NewClient(), server.WithCatalog(catalog)))
+	defer srv.Close()
+
+	out := server.SearchResponse{}
+	status := do(t, srv, http.MethodGet, "/memes/search?q=distracted+bf&limit=1", "", &out)
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(out.Matches) != 1 || out.Matches[0].Meme.Name != "Distracted Boyfriend" {
+		t.Errorf("expected Distracted Boyfriend, got %+v", out.Matches)
+	}
+
+	// The index should be rebuilt when the catalog changes.
+	api.SetMemes([]imgflipgo.Meme{{ID: "1", Name: "Brand New Template"}})
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status = do(t, srv, http.MethodGet, "/memes/search?q=brand+new", "", &out)
+	if status != http.StatusOK || len(out.Matches) != 1 || out.Matches[0].Meme.ID != "1" {
+		t.Errorf("expected the new template to be found, got %d: %+v", status, out.Matches)
+	}
+
+	for _, path := range []string{"/memes/search", "/memes/search?q=a&limit=0", "/memes/search?q=a&limit=x"} {
+		if status := do(t, srv, http.MethodGet, path, "", nil); status != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, status)
+		}
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{&imgflipgo.ValidationError{Problems: []error{errors.New("problem")}}, http.StatusBadRequest},
+		{&imgflipgo.APIError{Message: imgflipgotest.ErrMsgTemplateNotFound}, http.StatusNotFound},
+		{fmt.Errorf("waiting: %w", imgflipgo.ErrRateLimited), http.StatusTooManyRequests},
+		{&imgflipgo.APIError{StatusCode: http.StatusTooManyRequests}, http.StatusTooManyRequests},
+		{&imgflipgo.RetryError{Attempts: []error{errors.New("a"), context.DeadlineExceeded}}, http.StatusGatewayTimeout},
+		{fmt.Errorf("get_memes request: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{fmt.Errorf("caption_image request: %w", context.Canceled), server.StatusClientClosedRequest},
+		{&imgflipgo.RetryError{Attempts: []error{errors.New("a")}, Err: context.Canceled}, server.StatusClientClosedRequest},
+		{&imgflipgo.APIError{Message: imgflipgotest.ErrMsgInvalidCredentials}, http.StatusBadGateway},
+		{errors.New("connection refused"), http.StatusBadGateway},
+	}
+	for _, test := range tests {
+		if got := server.StatusCode(test.err); got != test.status {
+			t.Errorf("%v: expected %d, got %d", test.err, test.status, got)
+		}
+	}
+}
+
+func TestCaptionDeadline(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+	}))
+	defer slow.Close()
+
+	client := imgflipgo.NewClient(
+		imgflipgo.WithBaseURL(slow.URL),
+		imgflipgo.WithCredentials("user", "pass"),
+		imgflipgo.WithHTTPClient(&http.Client{Timeout: 10 * time.Millisecond}),
+	)
+	srv := httptest.NewServer(server.New(client))
+	defer srv.Close()
+
+	out := server.ErrorResponse{}
+	status := do(t, srv, http.MethodPost, "/caption", `{"template_id": "1", "text0": "a"}`, &out)
+	if status != http.StatusGatewayTimeout {
+		t.Errorf("expected 504, got %d: %s", status, out.Error)
+	}
+}
@@ -0,0 +1,298 @@
+// Package slack implements a Slack slash command that captions templates, e.g.
+//
+//	/meme drake | no tests | tests in prod
+//
+// The text before the first "|" picks a template by ID or name, as with
+// MemeCatalog.Find, and each "|" separated part after it becomes a TextBox. The
+// captioned image is posted to the channel as a Block Kit image block.
+//
+// Handler is an http.Handler to use as the command's request URL. It verifies
+// each request's signature with the app's signing secret before doing anything
+// else.
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2"
+	"github.com/Kardbord/imgflipgo/v2/internal/command"
+)
+
+// DefaultDeferAfter is how long a Handler waits for a caption before
+// acknowledging the command and posting the caption to its response_url
+// instead. Slack requires a response within three seconds.
+const DefaultDeferAfter = command.DefaultDeferAfter
+
+// DefaultTimeout bounds how long captioning a command may take, including
+// deferred commands.
+const DefaultTimeout = command.DefaultTimeout
+
+// MaxTimestampSkew is how far a request's timestamp may be from the current
+// time. Older requests are rejected to prevent replays.
+const MaxTimestampSkew = 5 * time.Minute
+
+// Errors reported when a request's signature can't be verified.
+var (
+	ErrMissingSignature = errors.New("missing Slack signature")
+	ErrInvalidSignature = errors.New("invalid Slack signature")
+	ErrStaleTimestamp   = errors.New("Slack request timestamp is too old")
+
+	// ErrNoSigningSecret is returned by Verify when the signing secret is
+	// empty, since anyone could sign requests with it.
+	ErrNoSigningSecret = errors.New("no Slack signing secret configured")
+)
+
+// Handler serves a Slack slash command. It is safe for concurrent use.
+type Handler struct {
+	signingSecret string
+	captioner     command.Captioner
+	httpClient    *http.Client
+	errorLog      *log.Logger
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithCatalog sets the catalog that templates are looked up in. By default, a
+// catalog caching templates for an hour is created for the Handler's Client.
+func WithCatalog(catalog *imgflipgo.MemeCatalog) Option {
+	return func(h *Handler) {
+		h.captioner.Catalog = catalog
+	}
+}
+
+// WithHTTPClient sets the client used to post deferred responses to Slack.
+// http.DefaultClient is used by default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(h *Handler) {
+		h.httpClient = httpClient
+	}
+}
+
+// WithDeferAfter sets how long the Handler waits for a caption before
+// deferring the response, see DefaultDeferAfter. If d is not positive, every
+// response is deferred.
+func WithDeferAfter(d time.Duration) Option {
+	return func(h *Handler) {
+		h.captioner.DeferAfter = d
+	}
+}
+
+// WithTimeout sets how long captioning a command may take, see DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.captioner.Timeout = d
+	}
+}
+
+// WithErrorLog sets the logger that failures to post deferred responses are
+// reported to. By default, the log package's standard logger is used.
+func WithErrorLog(l *log.Logger) Option {
+	return func(h *Handler) {
+		h.errorLog = l
+	}
+}
+
+// NewHandler creates a Handler that verifies requests with the Slack app's
+// signing secret and captions templates with client, or
+// imgflipgo.DefaultClient if client is nil. The client must be configured with
+// imgflipgo.WithCredentials. If signingSecret is empty, every request is
+// rejected.
+func NewHandler(signingSecret string, client *imgflipgo.Client, opts ...Option) *Handler {
+	if client == nil {
+		client = imgflipgo.DefaultClient
+	}
+	h := &Handler{
+		signingSecret: signingSecret,
+		httpClient:    http.DefaultClient,
+		errorLog:      log.Default(),
+	}
+	h.captioner.Client = client
+	h.captioner.DeferAfter = DefaultDeferAfter
+	h.captioner.Timeout = DefaultTimeout
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.captioner.Catalog == nil {
+		h.captioner.Catalog = imgflipgo.NewMemeCatalog(client, time.Hour)
+	}
+	return h
+}
+
+// Wait blocks until every deferred response has been posted.
+func (h *Handler) Wait() {
+	h.captioner.Wait()
+}
+
+// Message is a slash command response, see
+// https://api.slack.com/interactivity/slash-commands#responding_to_commands.
+type Message struct {
+	// ResponseType is "in_channel" to post the message to the channel, or
+	// "ephemeral" to show it only to the user who sent the command.
+	ResponseType string  `json:"response_type,omitempty"`
+	Text         string  `json:"text"`
+	Blocks       []Block `json:"blocks,omitempty"`
+}
+
+// Block is a Block Kit block. Only image blocks are used.
+type Block struct {
+	Type     string     `json:"type"`
+	ImageURL string     `json:"image_url,omitempty"`
+	AltText  string     `json:"alt_text,omitempty"`
+	Title    *PlainText `json:"title,omitempty"`
+}
+
+// PlainText is a Block Kit plain_text object.
+type PlainText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, command.MaxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = Verify(h.signingSecret, r.Header, body, time.Now())
+	if errors.Is(err, ErrNoSigningSecret) {
+		h.errorLog.Printf("slack: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := form.Get("command")
+	query, text, _ := strings.Cut(form.Get("text"), "|")
+	query, texts, err := command.Parse(query, text)
+	if err != nil {
+		writeMessage(w, ephemeral(fmt.Sprintf("Sorry, %v.\nUsage: `%s template | text | more text...`, e.g. `%s drake | no tests | tests in prod`", err, name, name)))
+		return
+	}
+
+	// If the caption takes too long, acknowledge the command now, and post the
+	// caption when it's ready.
+	responseURL := form.Get("response_url")
+	result, ok := h.captioner.Start(query, texts, func(result command.Result) {
+		err := h.post(responseURL, message(result, texts))
+		if err != nil {
+			h.errorLog.Printf("slack: posting deferred response: %v", err)
+		}
+	})
+	if ok {
+		writeMessage(w, message(result, texts))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// message returns the message to respond to a command with texts with.
+func message(result command.Result, texts []string) Message {
+	if result.Err != nil {
+		return ephemeral(result.Err.Error())
+	}
+	return Message{
+		ResponseType: "in_channel",
+		Text:         fmt.Sprintf("%s: %s", result.Meme.Name, result.Response.Data.URL),
+		Blocks: []Block{{
+			Type:     "image",
+			ImageURL: result.Response.Data.URL,
+			AltText:  strings.Join(texts, " / "),
+			Title:    &PlainText{Type: "plain_text", Text: result.Meme.Name},
+		}},
+	}
+}
+
+// post sends msg to a command's response_url.
+func (h *Handler) post(responseURL string, msg Message) error {
+	if responseURL == "" {
+		return errors.New("command has no response_url")
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	resp, err := h.httpClient.Post(responseURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("response_url returned %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
+
+func ephemeral(text string) Message {
+	return Message{ResponseType: "ephemeral", Text: text}
+}
+
+func writeMessage(w http.ResponseWriter, msg Message) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(msg)
+}
+
+// Signature returns the v0 signature of a request with the given timestamp
+// and body, as sent by Slack in the X-Slack-Signature header. It can be used
+// to send fake commands to a Handler, e.g. in tests.
+func Signature(signingSecret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the X-Slack-Signature and X-Slack-Request-Timestamp headers
+// of a request with the given body, as described at
+// https://api.slack.com/authentication/verifying-requests-from-slack.
+// Requests with a timestamp more than MaxTimestampSkew from now are rejected,
+// as is every request if signingSecret is empty.
+func Verify(signingSecret string, header http.Header, body []byte, now time.Time) error {
+	if signingSecret == "" {
+		return ErrNoSigningSecret
+	}
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	signature := header.Get("X-Slack-Signature")
+	if timestamp == "" || signature == "" {
+		return ErrMissingSignature
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > MaxTimestampSkew || skew < -MaxTimestampSkew {
+		return ErrStaleTimestamp
+	}
+	if !hmac.Equal([]byte(signature), []byte(Signature(signingSecret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
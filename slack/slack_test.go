@@ -0,0 +1,205 @@
+package slack_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kardbord/imgflipgo/v2/imgflipgotest"
+	"github.com/Kardbord/imgflipgo/v2/slack"
+)
+
+const signingSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// command builds a signed slash command request, as Slack would send it.
+func command(text, responseURL string) *http.Request {
+	return signedCommand(signingSecret, text, responseURL)
+}
+
+// signedCommand is like command, but signs the request with secret.
+func signedCommand(secret, text, responseURL string) *http.Request {
+	form := url.Values{
+		"command":      {"/meme"},
+		"text":         {text},
+		"user_id":      {"U2147483697"},
+		"response_url": {responseURL},
+	}
+	body := form.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req := httptest.NewRequest(http.MethodPost, "/slack/meme", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", slack.Signature(secret, timestamp, []byte(body)))
+	return req
+}
+
+// serve sends req to h and decodes the response message, if any.
+func serve(t *testing.T, h http.Handler, req *http.Request) (int, slack.Message) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	msg := slack.Message{}
+	if rec.Body.Len() > 0 && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &msg); err != nil {
+			t.Fatalf("%v: %s", err, rec.Body)
+		}
+	}
+	return rec.Code, msg
+}
+
+func TestCommand(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := slack.NewHandler(signingSecret, api.NewClient())
+
+	status, msg := serve(t, h, command("drake | no tests | tests in prod", ""))
+	if status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if msg.ResponseType != "in_channel" || len(msg.Blocks) != 1 {
+		t.Fatalf("expected an in_channel image, got %+v", msg)
+	}
+	block := msg.Blocks[0]
+	if block.Type != "image" || block.ImageURL == "" || block.AltText != "no tests / tests in prod" {
+		t.Errorf("unexpected block %+v", block)
+	}
+	if block.Title == nil || block.Title.Text != "Drake Hotline Bling" {
+		t.Errorf("expected the template name as the title, got %+v", block.Title)
+	}
+
+	requests := api.Requests()
+	req := requests[len(requests)-1]
+	if req.Form.Get("template_id") != "181913649" {
+		t.Errorf("expected drake to be captioned, got %q", req.Form.Get("template_id"))
+	}
+	if len(req.TextBoxes) != 2 || req.TextBoxes[0].Text != "no tests" || req.TextBoxes[1].Text != "tests in prod" {
+		t.Errorf("unexpected text boxes %+v", req.TextBoxes)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := slack.NewHandler(signingSecret, api.NewClient())
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", "no template specified"},
+		{"drake", "no text specified"},
+		{"drake | | ", "no text specified"},
+		{"zzzzzz | text", `No template matches "zzzzzz"`},
+	}
+	for _, test := range tests {
+		status, msg := serve(t, h, command(test.text, ""))
+		if status != http.StatusOK {
+			t.Errorf("%q: expected 200, got %d", test.text, status)
+		}
+		if msg.ResponseType != "ephemeral" || !strings.Contains(msg.Text, test.want) {
+			t.Errorf("%q: expected an ephemeral %q, got %+v", test.text, test.want, msg)
+		}
+	}
+}
+
+func TestDeferredCommand(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+
+	posted := make(chan slack.Message, 1)
+	responseURL := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := slack.Message{}
+		data, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Errorf("%v: %s", err, data)
+		}
+		posted <- msg
+	}))
+	defer responseURL.Close()
+
+	h := slack.NewHandler(signingSecret, api.NewClient(), slack.WithDeferAfter(0))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, command("distracted bf | new framework | me | old framework", responseURL.URL))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("expected an empty acknowledgement, got %d: %s", rec.Code, rec.Body)
+	}
+	h.Wait()
+
+	select {
+	case msg := <-posted:
+		if msg.ResponseType != "in_channel" || len(msg.Blocks) != 1 || msg.Blocks[0].Title.Text != "Distracted Boyfriend" {
+			t.Errorf("unexpected deferred message %+v", msg)
+		}
+	default:
+		t.Fatal("expected the caption to be posted to the response_url")
+	}
+
+	var logged strings.Builder
+	h = slack.NewHandler(signingSecret, api.NewClient(), slack.WithDeferAfter(0), slack.WithErrorLog(log.New(&logged, "", 0)))
+	h.ServeHTTP(httptest.NewRecorder(), command("drake | a", ""))
+	h.Wait()
+	if !strings.Contains(logged.String(), "no response_url") {
+		t.Errorf("expected a missing response_url to be logged, got %q", logged.String())
+	}
+}
+
+func TestVerify(t *testing.T) {
+	api := imgflipgotest.NewServer()
+	defer api.Close()
+	h := slack.NewHandler(signingSecret, api.NewClient())
+
+	req := command("drake | a", "")
+	req.Header.Set("X-Slack-Signature", "v0=0123")
+	if status, _ := serve(t, h, req); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a bad signature, got %d", status)
+	}
+	req = command("drake | a", "")
+	req.Header.Del("X-Slack-Signature")
+	if status, _ := serve(t, h, req); status != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a missing signature, got %d", status)
+	}
+	if len(api.Requests()) != 0 {
+		t.Error("expected unverified commands not to be captioned")
+	}
+
+	// An empty secret must not verify requests signed with it.
+	var logged strings.Builder
+	h = slack.NewHandler("", api.NewClient(), slack.WithErrorLog(log.New(&logged, "", 0)))
+	if status, _ := serve(t, h, signedCommand("", "drake | a", "")); status != http.StatusInternalServerError {
+		t.Errorf("expected 500 without a signing secret, got %d", status)
+	}
+	if !strings.Contains(logged.String(), "no Slack signing secret") {
+		t.Errorf("expected the missing secret to be logged, got %q", logged.String())
+	}
+	if len(api.Requests()) != 0 {
+		t.Error("expected unverified commands not to be captioned")
+	}
+
+	// The example from Slack's documentation.
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", "1531420618")
+	header.Set("X-Slack-Signature", "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503")
+	if err := slack.Verify(signingSecret, header, body, time.Unix(1531420618, 0)); err != nil {
+		t.Errorf("expected Slack's example to verify, got %v", err)
+	}
+	if err := slack.Verify(signingSecret, header, body, time.Unix(1531420618, 0).Add(time.Hour)); !errors.Is(err, slack.ErrStaleTimestamp) {
+		t.Errorf("expected ErrStaleTimestamp, got %v", err)
+	}
+	if err := slack.Verify(signingSecret, header, append(body, 'x'), time.Unix(1531420618, 0)); !errors.Is(err, slack.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	header.Set("X-Slack-Signature", slack.Signature("", "1531420618", body))
+	if err := slack.Verify("", header, body, time.Unix(1531420618, 0)); !errors.Is(err, slack.ErrNoSigningSecret) {
+		t.Errorf("expected ErrNoSigningSecret, got %v", err)
+	}
+}
@@ -3,8 +3,7 @@ package imgflipgo
 import (
 	"fmt"
 	"reflect"
-
-	"github.com/fatih/structtag"
+	"strings"
 )
 
 func getStructFieldJSONTag(v reflect.Type, fieldName string) (string, error) {
@@ -15,13 +14,10 @@ func getStructFieldJSONTag(v reflect.Type, fieldName string) (string, error) {
 	if !ok {
 		return "", fmt.Errorf(`struct %s has no field named "%s"`, v.Name(), fieldName)
 	}
-	tags, err := structtag.Parse(string(fieldMeta.Tag))
-	if err != nil {
-		return "", err
-	}
-	tagData, err := tags.Get("json")
-	if err != nil {
-		return "", err
+	tag, ok := fieldMeta.Tag.Lookup("json")
+	if !ok {
+		return "", fmt.Errorf(`field %s.%s has no json tag`, v.Name(), fieldName)
 	}
-	return tagData.Name, nil
+	name, _, _ := strings.Cut(tag, ",")
+	return name, nil
 }
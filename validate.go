@@ -0,0 +1,165 @@
+package imgflipgo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextBoxes is the maximum number of text boxes the API accepts per request.
+const MaxTextBoxes = 20
+
+// ErrInvalidRequest matches every *ValidationError.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// ValidationError is returned by Validate methods and lists every problem found
+// with a request.
+type ValidationError struct {
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		msgs[i] = problem.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidRequest, strings.Join(msgs, "; "))
+}
+
+// Is reports whether target is ErrInvalidRequest or matches any of the
+// problems.
+func (e *ValidationError) Is(target error) bool {
+	if target == ErrInvalidRequest {
+		return true
+	}
+	for _, problem := range e.Problems {
+		if errors.Is(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first problem that matches target, as with errors.As.
+func (e *ValidationError) As(target interface{}) bool {
+	for _, problem := range e.Problems {
+		if errors.As(problem, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// validator accumulates problems found while validating a request.
+type validator struct {
+	problems []error
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Errorf(format, args...))
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: v.problems}
+}
+
+func (v *validator) checkAuth(templateID, username, password string) {
+	if templateID == "" {
+		v.addf("missing template ID")
+	}
+	if username == "" {
+		v.addf("missing username")
+	}
+	if password == "" {
+		v.addf("missing password")
+	}
+}
+
+func (v *validator) checkTextBoxes(textBoxes []TextBox) {
+	if len(textBoxes) > MaxTextBoxes {
+		v.addf("%d text boxes provided, but at most %d are allowed", len(textBoxes), MaxTextBoxes)
+	}
+	for i, tb := range textBoxes {
+		geometry := 0
+		for _, field := range []*uint{tb.X, tb.Y, tb.Width, tb.Height} {
+			if field != nil {
+				geometry++
+			}
+		}
+		if geometry != 0 && geometry != 4 {
+			v.addf("text box %d: X, Y, Width, and Height must be specified together", i)
+		}
+		if tb.Color != nil && !tb.Color.Valid() {
+			v.addf("text box %d: Color %#x is greater than %#x", i, uint(*tb.Color), uint(MaxColor))
+		}
+		if tb.OutlineColor != nil && !tb.OutlineColor.Valid() {
+			v.addf("text box %d: OutlineColor %#x is greater than %#x", i, uint(*tb.OutlineColor), uint(MaxColor))
+		}
+		if tb.StartMs != nil && tb.EndMs != nil && *tb.StartMs > *tb.EndMs {
+			v.addf("text box %d: StartMs is after EndMs", i)
+		}
+	}
+}
+
+func hasText(textBoxes []TextBox) bool {
+	for _, tb := range textBoxes {
+		if tb.Text != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the request for problems that would cause the API to reject
+// it or silently ignore some of its parameters. If any are found, the returned
+// *ValidationError lists all of them.
+//
+// CaptionImage validates requests after applying the Client's credentials, so a
+// request relying on those will fail Validate when called directly.
+func (cr *CaptionRequest) Validate() error {
+	v := validator{}
+	v.checkAuth(cr.TemplateID, cr.Username, cr.Password)
+	v.checkTextBoxes(cr.TextBoxes)
+
+	hasTopOrBottom := cr.TopText != nil || cr.BottomText != nil
+	if hasTopOrBottom && len(cr.TextBoxes) > 0 {
+		v.addf("TopText and BottomText are ignored when TextBoxes are specified")
+	}
+	if (cr.TopText == nil || *cr.TopText == "") && (cr.BottomText == nil || *cr.BottomText == "") && !hasText(cr.TextBoxes) {
+		v.addf("no text specified")
+	}
+
+	return v.err()
+}
+
+// Validate checks the request for problems that would cause the API to reject
+// it. If any are found, the returned *ValidationError lists all of them.
+func (cgr *CaptionGifRequest) Validate() error {
+	v := validator{}
+	v.checkAuth(cgr.TemplateID, cgr.Username, cgr.Password)
+	v.checkTextBoxes(cgr.TextBoxes)
+	if !hasText(cgr.TextBoxes) {
+		v.addf("no text specified")
+	}
+	return v.err()
+}
+
+// Validate checks the request for problems that would cause the API to reject
+// it. If any are found, the returned *ValidationError lists all of them.
+func (ar *AIMemeRequest) Validate() error {
+	v := validator{}
+	if ar.Username == "" {
+		v.addf("missing username")
+	}
+	if ar.Password == "" {
+		v.addf("missing password")
+	}
+	if n := utf8.RuneCountInString(ar.PrefixText); n > MaxAIMemePrefixLen {
+		v.addf("PrefixText is %d characters, but at most %d are allowed", n, MaxAIMemePrefixLen)
+	}
+	return v.err()
+}
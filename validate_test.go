@@ -0,0 +1,97 @@
+package imgflipgo_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Kardbord/imgflipgo/v2"
+)
+
+func TestValidateValidRequest(t *testing.T) {
+	req := (&imgflipgo.CaptionRequest{
+		TemplateID: testTemplateID,
+		Username:   "user",
+		Password:   "pass",
+	}).SetTopText("Top Text")
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	req = &imgflipgo.CaptionRequest{
+		TemplateID: testTemplateID,
+		Username:   "user",
+		Password:   "pass",
+		TextBoxes: []imgflipgo.TextBox{
+			{},
+			*(&imgflipgo.TextBox{Text: "Bottom Text"}).SetX(0).SetY(0).SetWidth(100).SetHeight(50),
+		},
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	req := (&imgflipgo.CaptionRequest{
+		TextBoxes: make([]imgflipgo.TextBox, imgflipgo.MaxTextBoxes+1),
+	}).SetTopText("")
+	req.TextBoxes[3].SetX(10).SetWidth(100)
+
+	err := req.Validate()
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+	var validationErr *imgflipgo.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a *ValidationError, got %T", err)
+	}
+
+	expected := []string{
+		"missing template ID",
+		"missing username",
+		"missing password",
+		"at most 20 are allowed",
+		"text box 3: X, Y, Width, and Height must be specified together",
+		"TopText and BottomText are ignored",
+		"no text specified",
+	}
+	if len(validationErr.Problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %d: %v", len(expected), len(validationErr.Problems), err)
+	}
+	for i := range expected {
+		if !strings.Contains(validationErr.Problems[i].Error(), expected[i]) {
+			t.Errorf("expected problem %d to contain %q, got %q", i, expected[i], validationErr.Problems[i])
+		}
+	}
+}
+
+func TestCaptionImageValidatesBeforeSending(t *testing.T) {
+	client := imgflipgo.NewClient(imgflipgo.WithBaseURL("http://127.0.0.1:0"))
+	resp, err := client.CaptionImage(&imgflipgo.CaptionRequest{TemplateID: testTemplateID})
+	expectFailure(t, resp, err)
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) {
+		t.Fatalf("expected ErrInvalidRequest, got %v", err)
+	}
+}
+
+func TestValidationErrorMatchesProblems(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	apiErr := &imgflipgo.APIError{Message: "bad"}
+	err := fmt.Errorf("wrapped: %w", &imgflipgo.ValidationError{Problems: []error{
+		fmt.Errorf("first: %w", sentinel),
+		fmt.Errorf("second: %w", apiErr),
+	}})
+
+	if !errors.Is(err, imgflipgo.ErrInvalidRequest) || !errors.Is(err, sentinel) {
+		t.Errorf("expected ErrInvalidRequest and the sentinel to match, got %v", err)
+	}
+	if errors.Is(err, imgflipgo.ErrRateLimited) {
+		t.Errorf("expected unrelated errors not to match %v", err)
+	}
+	var got *imgflipgo.APIError
+	if !errors.As(err, &got) || got != apiErr {
+		t.Errorf("expected the wrapped *APIError, got %v", got)
+	}
+}